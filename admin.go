@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// startTime is captured at the top of main so uptime covers the whole process
+var startTime time.Time
+
+// handleReload re-reads the configuration and swaps it in
+func (b *Bot) handleReload(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if err := b.reloadConfig("user " + m.Author.ID); err != nil {
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "reload_failed", err)))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "reload_done"))
+}
+
+// reloadConfig re-reads the configuration for !reload and SIGHUP and swaps it in. An invalid
+// configuration is rejected and the current one kept. Settings baked into long-lived components
+// at startup, such as caches, rate limits and sharding, keep their old values.
+func (b *Bot) reloadConfig(trigger string) error {
+	old := b.config.Load()
+	config, err := reloadConfiguration(old.EnvFile)
+	if err != nil {
+		slog.Error("Error reloading configuration, keeping the current one", "trigger", trigger, "error", err)
+		return err
+	}
+
+	configureLogging(config.Debug)
+	b.config.Store(config)
+	slog.Info("Configuration reloaded", "trigger", trigger, "changed", configChanges(old, config))
+
+	// Let the schedulers pick up a new daily verse time or auto verse interval
+	notify(b.reloaded)
+	notify(b.autoVerseReloaded)
+	return nil
+}
+
+// notify signals ch without blocking; one pending notice is enough
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// configChanges describes each field that differs between old and new, e.g. "Prefix: ! -> ?".
+// Secrets are only reported as changed so they never reach the logs.
+func configChanges(old, new *AppConfig) []string {
+	var changes []string
+	ov, nv := reflect.ValueOf(*old), reflect.ValueOf(*new)
+	for i := 0; i < ov.NumField(); i++ {
+		name := ov.Type().Field(i).Name
+		was, now := ov.Field(i).Interface(), nv.Field(i).Interface()
+		switch {
+		case reflect.DeepEqual(was, now):
+		case name == "DiscordToken", name == "TTSAPIKey", name == "DailyVerseWebhookURL":
+			changes = append(changes, name+" changed")
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, was, now))
+		}
+	}
+	return changes
+}
+
+// commandUsage counts how often each command has run since startup
+type commandUsage struct {
+	mu     sync.Mutex
+	counts map[string]int
+	total  int
+}
+
+// commandCount is one command's entry in the !stats top list
+type commandCount struct {
+	Name  string
+	Count int
+}
+
+// Add records one run of the named command
+func (u *commandUsage) Add(name string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.counts == nil {
+		u.counts = make(map[string]int)
+	}
+	u.counts[name]++
+	u.total++
+}
+
+// Top returns the n most used commands, busiest first, and the total number of runs
+func (u *commandUsage) Top(n int) ([]commandCount, int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	top := make([]commandCount, 0, len(u.counts))
+	for name, count := range u.counts {
+		top = append(top, commandCount{Name: name, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top, u.total
+}
+
+// countCommand records a command run for both Prometheus and !stats. Every dispatcher calls it
+// exactly once per command so the two never disagree.
+func (b *Bot) countCommand(name string) {
+	botMetrics.observeCommand(name)
+	b.usage.Add(name)
+}
+
+// handleStats replies with a quick health snapshot: uptime, servers, users and memory
+func (b *Bot) handleStats(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	b.replyEmbed(ctx, s, m, b.createStatsEmbed(ctx, s))
+}
+
+// handleMaintenance turns maintenance mode on or off. The flag lives in memory only, so a
+// restart, such as the one finishing an upgrade, always comes back out of maintenance.
+func (b *Bot) handleMaintenance(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) != 1 || !(strings.EqualFold(args[0], "on") || strings.EqualFold(args[0], "off")) {
+		b.reply(ctx, s, m, localize(ctx, "usage_maintenance", b.prefix(m.GuildID)))
+		return
+	}
+
+	on := strings.EqualFold(args[0], "on")
+	b.maintenance.Store(on)
+	slog.InfoContext(ctx, "Maintenance mode changed", "on", on, "user_id", m.Author.ID)
+	if on {
+		b.reply(ctx, s, m, localize(ctx, "maintenance_on", b.prefix(m.GuildID)))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "maintenance_off"))
+}
+
+// createStatsEmbed builds the !stats embed from session state and the Go runtime
+func (b *Bot) createStatsEmbed(ctx context.Context, s *discordgo.Session) *discordgo.MessageEmbed {
+	s.State.RLock()
+	guilds := len(s.State.Guilds)
+	users := 0
+	for _, g := range s.State.Guilds {
+		users += g.MemberCount
+	}
+	s.State.RUnlock()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	top, total := b.usage.Top(StatsTopCommands)
+	var topList strings.Builder
+	for _, c := range top {
+		topList.WriteString(fmt.Sprintf("`%s` — %d\n", c.Name, c.Count))
+	}
+	if topList.Len() == 0 {
+		topList.WriteString("—")
+	}
+
+	maintenance := localize(ctx, "stats_off")
+	if b.maintenance.Load() {
+		maintenance = localize(ctx, "stats_on")
+	}
+
+	uptime := time.Since(startTime).Round(time.Second)
+	return &discordgo.MessageEmbed{
+		Title: localize(ctx, "stats_title"),
+		Color: b.config.Load().EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: localize(ctx, "stats_uptime"), Value: uptime.String(), Inline: true},
+			{Name: localize(ctx, "stats_guilds"), Value: strconv.Itoa(guilds), Inline: true},
+			{Name: localize(ctx, "stats_users"), Value: strconv.Itoa(users), Inline: true},
+			{Name: localize(ctx, "stats_memory"), Value: localize(ctx, "stats_memory_value", mebibytes(mem.Alloc), mebibytes(mem.Sys)), Inline: true},
+			{Name: localize(ctx, "stats_commands"), Value: strconv.Itoa(total), Inline: true},
+			{Name: localize(ctx, "stats_maintenance"), Value: maintenance, Inline: true},
+			{Name: localize(ctx, "stats_top"), Value: topList.String()},
+		},
+	}
+}
+
+// mebibytes converts a byte count for display
+func mebibytes(n uint64) float64 {
+	return float64(n) / (1 << 20)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// anotherVerseIDPrefix starts the custom ID of the "Another" button. The rest of the ID is the
+// translation and, for !random or a book group, the book or group, so the button works without any server-side state.
+const anotherVerseIDPrefix = "verse:another:"
+
+// anotherVerseID builds the custom ID for a verse in translation, optionally limited to a book or one of bookGroups
+func anotherVerseID(translation, bookID string) string {
+	id := anotherVerseIDPrefix + translation
+	if bookID != "" {
+		id += ":" + bookID
+	}
+	return id
+}
+
+// anotherVerseComponents renders the "Another" button shown under random verses
+func anotherVerseComponents(ctx context.Context, customID string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: localize(ctx, "another_button"), Emoji: &discordgo.ComponentEmoji{Name: "🔄"}, Style: discordgo.SecondaryButton, CustomID: customID},
+			},
+		},
+	}
+}
+
+// handleAnotherButton replaces the verse in the clicked message with a fresh random one
+func (b *Bot) handleAnotherButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx := withLocale(b.ctx, b.interactionLocale(i))
+	userID := interactionUserID(i)
+
+	// The button is a way of running !verse, so it follows that command's restrictions, which
+	// may have changed since the button was posted
+	if verseCommand, ok := b.findCommand("verse"); ok && b.commandDisabled(i.GuildID, verseCommand) {
+		respondEphemeral(s, i, localize(ctx, "command_disabled"))
+		return
+	}
+	if !b.channelAllowed(i.GuildID, i.ChannelID) {
+		respondEphemeral(s, i, localize(ctx, "channel_not_allowed"))
+		return
+	}
+	if verseCommand, ok := b.findCommand("verse"); ok && b.inMaintenance(userID, verseCommand) {
+		respondEphemeral(s, i, localize(ctx, "maintenance"))
+		return
+	}
+	if ok, wait := b.verseLimiter.Allow(userID); !ok {
+		respondEphemeral(s, i, rateLimitReply(ctx, wait))
+		return
+	}
+
+	// Acknowledge straight away since the fetch can outlast Discord's 3 second window
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	if err != nil {
+		slog.Error("Error acknowledging button", "interaction_id", i.ID, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.config.Load().CommandTimeout)
+	defer cancel()
+
+	translation, bookID, _ := strings.Cut(strings.TrimPrefix(i.MessageComponentData().CustomID, anotherVerseIDPrefix), ":")
+	var verse *BibleVerse
+	if bookIDs, ok := bookGroups[bookID]; ok {
+		verse, err = b.verses.RandomFromBooks(ctx, bookIDs, translation)
+	} else if bookID != "" {
+		verse, err = b.verses.RandomFromBook(ctx, bookID, translation)
+	} else {
+		verse, err = b.verses.Random(ctx, translation)
+	}
+	if err != nil {
+		_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Content: verseErrorReply(ctx, err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			slog.Error("Error sending button followup", "interaction_id", i.ID, "error", err)
+		}
+		return
+	}
+
+	b.recent.Remember(userID, verse)
+	edit, p := b.anotherVerseEdit(ctx, i, verse)
+	if _, err = s.InteractionResponseEdit(i.Interaction, edit); err != nil {
+		slog.Error("Error editing verse message", "interaction_id", i.ID, "error", err)
+		return
+	}
+	b.posted.Set(i.Message.ID, verse)
+	if p != nil {
+		b.paginators.Add(i.Message.ID, p)
+	}
+}
+
+// anotherVerseEdit builds the edit that swaps verse into the clicked message in the guild's verse style,
+// with the paginator to register once it is made. Everything is replaced, including any image card,
+// since the style may have changed since the message was sent.
+func (b *Bot) anotherVerseEdit(ctx context.Context, i *discordgo.InteractionCreate, verse *BibleVerse) (*discordgo.WebhookEdit, *paginator) {
+	send, p := b.verseMessage(i.GuildID, verse)
+	if p == nil {
+		send.Components = anotherVerseComponents(ctx, i.MessageComponentData().CustomID)
+	}
+	if b.mentionRequester(i.GuildID) {
+		mentionUser(send, interactionUserID(i))
+	}
+
+	embeds := append([]*discordgo.MessageEmbed{}, send.Embeds...)
+	return &discordgo.WebhookEdit{
+		Content:         &send.Content,
+		Embeds:          &embeds,
+		Components:      &send.Components,
+		Files:           send.Files,
+		Attachments:     &[]*discordgo.MessageAttachment{},
+		AllowedMentions: send.AllowedMentions,
+	}, p
+}
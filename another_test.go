@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAnotherVerseEditFollowsVerseStyle(t *testing.T) {
+	tests := []struct {
+		style      string
+		wantEmbeds int
+		wantFiles  int
+		wantText   bool
+	}{
+		{VerseStyleEmbed, 1, 0, false},
+		{VerseStylePlain, 0, 0, true},
+		{VerseStyleImage, 1, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			b := newTestBot(t, &fakeProvider{verse: john316})
+			if err := b.settings.Update("guild", func(gc *GuildConfig) { gc.VerseStyle = tt.style }); err != nil {
+				t.Fatal(err)
+			}
+			click := newButtonClick(testAllowedChannel, anotherVerseID("web", ""))
+
+			edit, p := b.anotherVerseEdit(context.Background(), click, john316)
+			if p != nil {
+				t.Fatal("a single verse got a paginator")
+			}
+			if n := len(*edit.Embeds); n != tt.wantEmbeds {
+				t.Errorf("edit has %d embeds, want %d", n, tt.wantEmbeds)
+			}
+			if n := len(edit.Files); n != tt.wantFiles {
+				t.Errorf("edit has %d files, want %d", n, tt.wantFiles)
+			}
+			if got := strings.Contains(*edit.Content, john316.Text()); got != tt.wantText {
+				t.Errorf("edit content = %q, want verse text: %v", *edit.Content, tt.wantText)
+			}
+			// The previous message's image card, if any, goes away
+			if edit.Attachments == nil || len(*edit.Attachments) != 0 {
+				t.Error("edit keeps the old attachments")
+			}
+
+			// The button stays so the verse can be rerolled again
+			row, ok := (*edit.Components)[0].(discordgo.ActionsRow)
+			if !ok || len(row.Components) != 1 || row.Components[0].(discordgo.Button).CustomID != click.MessageComponentData().CustomID {
+				t.Errorf("edit components = %+v, want the Another button", *edit.Components)
+			}
+		})
+	}
+}
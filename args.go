@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitArgs splits a command line into words like a shell would, except that only double quotes
+// group words: `verse "Song of Solomon 1:1"` yields two words. A backslash escapes a double quote
+// or another backslash and is kept as-is before anything else. An unbalanced quote runs to the
+// end of the line rather than being an error, so a stray quote never loses the user's input.
+func splitArgs(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words
+}
+
+// takeFlag removes every occurrence of flag, e.g. "--audio", from args and reports whether there was one
+func takeFlag(args []string, flag string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if strings.EqualFold(arg, flag) {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"words", "verse John 3:16", []string{"verse", "John", "3:16"}},
+		{"extra whitespace", "  verse \t kjv  ", []string{"verse", "kjv"}},
+		{"quoted", `verse "Song of Solomon 1:1"`, []string{"verse", "Song of Solomon 1:1"}},
+		{"quote inside a word", `verse Song" of "Solomon`, []string{"verse", "Song of Solomon"}},
+		{"empty quotes", `verse ""`, []string{"verse", ""}},
+		{"unbalanced quote", `verse "Song of Solomon 1:1`, []string{"verse", "Song of Solomon 1:1"}},
+		{"unbalanced quote at the end", `verse kjv "`, []string{"verse", "kjv", ""}},
+		{"unbalanced quote keeps spaces", `feedback "  hello  there`, []string{"feedback", "  hello  there"}},
+		{"escaped quote", `feedback say \"hi\"`, []string{"feedback", "say", `"hi"`}},
+		{"escaped quote inside quotes", `feedback "say \"hi\" now"`, []string{"feedback", `say "hi" now`}},
+		{"escaped backslash", `a\\b`, []string{`a\b`}},
+		{"escaped backslash before a quote", `"a\\" b`, []string{`a\`, "b"}},
+		{"backslash before anything else", `C:\verses\john.txt`, []string{`C:\verses\john.txt`}},
+		{"trailing backslash", `verse \`, []string{"verse", `\`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitArgs(tt.line); !slices.Equal(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Outcomes recorded in the audit log
+const (
+	AuditCompleted   = "completed"
+	AuditTimedOut    = "timed_out"
+	AuditDisabled    = "disabled"
+	AuditNotAllowed  = "not_allowed"
+	AuditMaintenance = "maintenance"
+)
+
+// auditLog writes one JSON line per command to a size-rotated file, apart from the operational
+// logs. A nil auditLog records nothing, so callers need not check whether AUDIT_LOG_FILE is set.
+type auditLog struct {
+	file   *lumberjack.Logger
+	logger *slog.Logger
+}
+
+// newAuditLog opens the audit log described by config, or returns nil when AUDIT_LOG_FILE is unset
+func newAuditLog(config *AppConfig) *auditLog {
+	if config.AuditLogFile == "" {
+		return nil
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   config.AuditLogFile,
+		MaxSize:    config.AuditLogMaxSizeMB,
+		MaxBackups: config.AuditLogMaxBackups,
+	}
+	return &auditLog{
+		file:   file,
+		logger: slog.New(requestIDHandler{slog.NewJSONHandler(file, nil)}),
+	}
+}
+
+// Record logs a command run and how it ended, along with the request ID carried by ctx
+func (a *auditLog) Record(ctx context.Context, userID, guildID, channelID, command, outcome string) {
+	if a == nil {
+		return
+	}
+	a.logger.InfoContext(ctx, "command", "user_id", userID, "guild_id", guildID, "channel_id", channelID, "command", command, "outcome", outcome)
+}
+
+// Close flushes and closes the log file
+func (a *auditLog) Close() {
+	if a == nil {
+		return
+	}
+	if err := a.file.Close(); err != nil {
+		slog.Error("Error closing audit log", "error", err)
+	}
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SupportedTranslations lists the translation identifiers accepted by the Bible API
+var SupportedTranslations = []string{
+	"web", "kjv", "asv", "bbe", "darby", "dra", "ylt", "webbe",
+	"oeb-us", "oeb-cw", "clementine", "almeida", "rccv", "cherokee", "cuv", "bkr",
+}
+
+// isSupportedTranslation reports whether the identifier is a known translation
+func isSupportedTranslation(id string) bool {
+	for _, t := range SupportedTranslations {
+		if t == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Translation describes the Bible translation a verse was taken from
+type Translation struct {
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+	Language   string `json:"language"`
+	License    string `json:"license"`
+}
+
+// RandomVerse holds a single verse returned by the Bible API
+type RandomVerse struct {
+	BookID  string `json:"book_id"`
+	Book    string `json:"book"`
+	Chapter int    `json:"chapter"`
+	Verse   int    `json:"verse"`
+	Text    string `json:"text"`
+}
+
+// Reference formats the verse location, e.g. "John 3:16"
+func (v RandomVerse) Reference() string {
+	return fmt.Sprintf("%s %d:%d", v.Book, v.Chapter, v.Verse)
+}
+
+// BibleVerse represents the structured data from the Bible API
+type BibleVerse struct {
+	Translation Translation `json:"translation"`
+	RandomVerse RandomVerse `json:"random_verse"`
+
+	// Passage fields are only populated for reference lookups
+	Reference string        `json:"-"`
+	Verses    []RandomVerse `json:"-"`
+
+	// Truncated is set when Verses holds only the start of the requested range
+	Truncated bool `json:"-"`
+}
+
+// Title returns the reference of the passage, e.g. "Genesis 1:1-3"
+func (b *BibleVerse) Title() string {
+	if b.Reference != "" {
+		return b.Reference
+	}
+	return b.RandomVerse.Reference()
+}
+
+// Text returns the passage text, numbering each verse when there are several. A passage
+// that runs into another chapter, such as John 3:16-4:2, gets a marker where the chapter changes.
+func (b *BibleVerse) Text() string {
+	var builder strings.Builder
+	if len(b.Verses) <= 1 {
+		builder.WriteString(b.RandomVerse.Text)
+	} else {
+		for i, v := range b.Verses {
+			switch {
+			case i == 0:
+			case v.Chapter != b.Verses[i-1].Chapter:
+				builder.WriteString(fmt.Sprintf("\n\n**Chapter %d**\n", v.Chapter))
+			default:
+				builder.WriteString(" ")
+			}
+			builder.WriteString(fmt.Sprintf("**%d** %s", v.Verse, v.Text))
+		}
+	}
+
+	// The note goes on whenever verses were dropped, even when only one is left
+	switch {
+	case !b.Truncated:
+	case len(b.Verses) == 1:
+		builder.WriteString("\n\n*(showing the first verse)*")
+	default:
+		builder.WriteString(fmt.Sprintf("\n\n*(showing first %d verses)*", len(b.Verses)))
+	}
+	return builder.String()
+}
+
+// limitVerses returns the passage cut down to its first n verses, leaving b itself untouched
+// so a cached passage can be limited again under a different MAX_RANGE_VERSES
+func (b *BibleVerse) limitVerses(n int) *BibleVerse {
+	if len(b.Verses) <= n {
+		return b
+	}
+	limited := *b
+	limited.Verses = b.Verses[:n]
+	limited.Truncated = true
+	return &limited
+}
+
+// Verse text formats selectable with VERSE_FORMAT
+const (
+	FormatBlockquote = "blockquote"
+	FormatPlain      = "plain"
+	FormatCode       = "code"
+)
+
+// VerseFormats lists the accepted VERSE_FORMAT values
+var VerseFormats = []string{FormatBlockquote, FormatPlain, FormatCode}
+
+// isVerseFormat reports whether format is one of VerseFormats
+func isVerseFormat(format string) bool {
+	for _, f := range VerseFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// formatVerseText renders verse text as a Markdown blockquote, a code block or as-is.
+// Code blocks show Markdown literally, so the bold verse numbers are unwrapped first.
+func formatVerseText(text, format string) string {
+	switch format {
+	case FormatBlockquote:
+		return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	case FormatCode:
+		return "```\n" + strings.ReplaceAll(text, "**", "") + "\n```"
+	default:
+		return text
+	}
+}
+
+// passageResponse is the payload returned by the reference lookup endpoint
+type passageResponse struct {
+	Reference string `json:"reference"`
+	Verses    []struct {
+		BookID   string `json:"book_id"`
+		BookName string `json:"book_name"`
+		Chapter  int    `json:"chapter"`
+		Verse    int    `json:"verse"`
+		Text     string `json:"text"`
+	} `json:"verses"`
+	TranslationID   string `json:"translation_id"`
+	TranslationName string `json:"translation_name"`
+	TranslationNote string `json:"translation_note"`
+}
+
+// errPassageNotFound is returned when the API does not recognise a reference
+var errPassageNotFound = errors.New("passage not found")
+
+// errEmptyVerse is returned when the API responds without any verse text
+var errEmptyVerse = errors.New("API response contained no verse")
+
+// errResponseTooLarge is returned when an API response does not fit in maxResponseBytes
+var errResponseTooLarge = errors.New("API response too large")
+
+// Fetch failures are wrapped in one of these so callers can tell them apart with errors.Is
+var (
+	// errUpstreamUnavailable means the API could not be reached or answered with an error status
+	errUpstreamUnavailable = errors.New("bible verse API unavailable")
+	// errTimeout means the API did not answer in time
+	errTimeout = errors.New("bible verse API timed out")
+	// errParse means the API answered with a body that could not be decoded
+	errParse = errors.New("bible verse API response could not be parsed")
+)
+
+// errUnknownTranslation is returned when a translation is not in SupportedTranslations
+var errUnknownTranslation = errors.New("unknown translation")
+
+// maxResponseBytes is the most of an API response that will be read, set from MAX_RESPONSE_BYTES
+var maxResponseBytes int64 = DefaultMaxResponseBytes
+
+// userAgent is sent with every API request, set from HTTP_USER_AGENT
+var userAgent = "verseondemanddiscord/" + version
+
+// apiRequests caps how many Bible API requests are in flight at once, set from MAX_CONCURRENT_REQUESTS
+var apiRequests = newSemaphore(DefaultMaxAPIRequests)
+
+// semaphore limits concurrency to its capacity
+type semaphore chan struct{}
+
+// newSemaphore creates a semaphore admitting n holders at a time
+func newSemaphore(n int) semaphore {
+	return make(semaphore, n)
+}
+
+// Acquire waits for a free slot, giving up with ctx's error if ctx is done first
+func (sem semaphore) Acquire(ctx context.Context) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (sem semaphore) Release() {
+	<-sem
+}
+
+// httpClient is shared by all Bible API requests so connections are pooled and kept alive
+var httpClient = newHTTPClient()
+
+// newHTTPClient builds the HTTP client used for outbound API calls
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 20
+	transport.MaxIdleConnsPerHost = 10
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: transport,
+	}
+}
+
+// fetchFromAPI performs a GET request against the Bible API and decodes the JSON body into v.
+// Network errors and 5xx responses are retried with exponential backoff within FetchDeadline.
+func fetchFromAPI(ctx context.Context, endpoint string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, FetchDeadline)
+	defer cancel()
+
+	var body []byte
+	var err error
+	delay := RetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		var retryable bool
+		body, retryable, err = fetchOnce(ctx, endpoint)
+		if err == nil || !retryable || attempt == MaxFetchAttempts {
+			break
+		}
+
+		slog.WarnContext(ctx, "Bible API attempt failed, retrying", "attempt", attempt, "max_attempts", MaxFetchAttempts, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%w: retry deadline exceeded: %w", errTimeout, err)
+		}
+		delay *= 2
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		botMetrics.observeAPIError("parse")
+		return fmt.Errorf("%w: %w", errParse, err)
+	}
+
+	return nil
+}
+
+// fetchOnce makes a single request and reports whether a failure is worth retrying
+func fetchOnce(ctx context.Context, endpoint string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid bible verse API request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	// Requests beyond the cap queue here rather than piling onto the API
+	if err := apiRequests.Acquire(ctx); err != nil {
+		return nil, false, fmt.Errorf("%w: waiting for a free request slot: %w", errTimeout, err)
+	}
+	defer apiRequests.Release()
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	botMetrics.observeAPILatency(time.Since(start))
+	if err != nil {
+		botMetrics.observeAPIError("network")
+
+		// Network errors are transient unless our own deadline has passed
+		kind := errUpstreamUnavailable
+		if isTimeout(err) {
+			kind = errTimeout
+		}
+		return nil, ctx.Err() == nil, fmt.Errorf("%w: %w", kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		botMetrics.observeAPIError("not_found")
+		return nil, false, errPassageNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		botMetrics.observeAPIError(fmt.Sprintf("status_%dxx", resp.StatusCode/100))
+		retryable := resp.StatusCode >= http.StatusInternalServerError
+		return nil, retryable, fmt.Errorf("%w: status %d", errUpstreamUnavailable, resp.StatusCode)
+	}
+
+	// Read one byte past the limit so a response that would be truncated can be told apart from one that fits
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		botMetrics.observeAPIError("read")
+		return nil, true, fmt.Errorf("%w: reading response: %w", errUpstreamUnavailable, err)
+	}
+	if int64(len(body)) > maxResponseBytes {
+		botMetrics.observeAPIError("too_large")
+		return nil, false, fmt.Errorf("%w: more than %d bytes", errResponseTooLarge, maxResponseBytes)
+	}
+
+	return body, false, nil
+}
+
+// isTimeout reports whether a request failed because a deadline passed
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// getBibleVerse fetches a random Bible verse in the given translation with robust error handling
+func getBibleVerse(ctx context.Context, translation string) (*BibleVerse, error) {
+	endpoint := fmt.Sprintf("%s/data/%s/random", BibleAPIBase, url.PathEscape(translation))
+	return fetchRandomVerse(ctx, endpoint)
+}
+
+// fetchRandomVerse fetches and validates a verse from one of the random endpoints
+func fetchRandomVerse(ctx context.Context, endpoint string) (*BibleVerse, error) {
+	var verse BibleVerse
+	if err := fetchFromAPI(ctx, endpoint, &verse); err != nil {
+		return nil, err
+	}
+
+	// The API sometimes pads verse text with trailing newlines
+	verse.RandomVerse.Text = strings.TrimSpace(verse.RandomVerse.Text)
+
+	// Error payloads and unexpected shapes still decode, just without a verse
+	if verse.RandomVerse.Text == "" {
+		botMetrics.observeAPIError("empty")
+		return nil, errEmptyVerse
+	}
+
+	return &verse, nil
+}
+
+// unknownBookError is returned when a book name does not match any book of the Bible
+type unknownBookError struct {
+	Name       string
+	Suggestion string
+}
+
+func (e *unknownBookError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown book %q, did you mean %s?", e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown book %q", e.Name)
+}
+
+// getRandomVerseFromBook fetches a random verse from the named book, e.g. "Psalms"
+func getRandomVerseFromBook(ctx context.Context, bookName, translation string) (*BibleVerse, error) {
+	book, ok := findBook(bookName)
+	if !ok {
+		err := &unknownBookError{Name: bookName}
+		if suggestion, ok := suggestBook(bookName); ok {
+			err.Suggestion = suggestion.Name
+		}
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/data/%s/random/%s", BibleAPIBase, url.PathEscape(translation), book.ID)
+	return fetchRandomVerse(ctx, endpoint)
+}
+
+// getRandomVerseFromBooks fetches a random verse from any of the books with the given IDs.
+// The random endpoint takes them as a comma-separated list.
+func getRandomVerseFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error) {
+	endpoint := fmt.Sprintf("%s/data/%s/random/%s", BibleAPIBase, url.PathEscape(translation), strings.Join(bookIDs, ","))
+	return fetchRandomVerse(ctx, endpoint)
+}
+
+// getVerseByReference fetches a specific passage such as "John 3:16" or "Genesis 1:1-3"
+func getVerseByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	var passage passageResponse
+	endpoint := fmt.Sprintf("%s/%s?translation=%s", BibleAPIBase, url.PathEscape(ref), url.QueryEscape(translation))
+	if err := fetchFromAPI(ctx, endpoint, &passage); err != nil {
+		return nil, err
+	}
+
+	if len(passage.Verses) == 0 {
+		return nil, errPassageNotFound
+	}
+
+	// Normalize the passage into the same shape as a random verse
+	verse := &BibleVerse{
+		Translation: Translation{
+			Identifier: passage.TranslationID,
+			Name:       passage.TranslationName,
+			License:    passage.TranslationNote,
+		},
+		Reference: passage.Reference,
+	}
+	for _, v := range passage.Verses {
+		verse.Verses = append(verse.Verses, RandomVerse{
+			BookID:  v.BookID,
+			Book:    v.BookName,
+			Chapter: v.Chapter,
+			Verse:   v.Verse,
+			Text:    strings.TrimSpace(v.Text),
+		})
+	}
+	verse.RandomVerse = verse.Verses[0]
+
+	return verse, nil
+}
+
+// embedTimestamp formats t the way Discord expects an embed timestamp, as RFC 3339 in UTC
+func embedTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// createVerseEmbed generates a rich, informative Discord embed
+func (b *Bot) createVerseEmbed(verse *BibleVerse) *discordgo.MessageEmbed {
+	return b.createVersePageEmbed(verse, verse.Text(), 1, 1)
+}
+
+// createVersePageEmbed renders one page of a passage, noting the page number when there are several
+func (b *Bot) createVersePageEmbed(verse *BibleVerse, text string, page, total int) *discordgo.MessageEmbed {
+	config := b.config.Load()
+
+	// Title the embed with the reference itself, e.g. "John 3:16 (WEB)"
+	title := fmt.Sprintf("%s (%s)", verse.Title(), strings.ToUpper(verse.Translation.Identifier))
+	if config.EmbedEmoji {
+		title = "📖 " + title
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: formatVerseText(text, config.VerseFormat),
+		Color:       config.EmbedColor,
+	}
+	if config.EmbedTimestamp {
+		embed.Timestamp = embedTimestamp(time.Now())
+	}
+
+	// Credit the translation by its full name, e.g. "World English Bible"
+	if verse.Translation.Name != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: verse.Translation.Name}
+	}
+	if config.EmbedThumbnailURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: config.EmbedThumbnailURL}
+	}
+
+	// The configured footer is followed by the page number on multi-page passages
+	footer := config.EmbedFooter
+	if total > 1 {
+		footer = strings.TrimPrefix(fmt.Sprintf("%s • Page %d/%d", footer, page, total), " • ")
+	}
+	if footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	}
+	return limitEmbed(embed)
+}
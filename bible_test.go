@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStatusServer serves the statuses in turn, repeating the last one, and counts the requests it gets.
+// A 200 answers with body.
+func newStatusServer(t *testing.T, body string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, body)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestFetchFromAPIRetries(t *testing.T) {
+	const body = `{"random_verse": {"book": "John", "chapter": 3, "verse": 16, "text": "For God so loved the world"}}`
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      error
+		wantRequests int32
+	}{
+		{"succeeds first time", []int{200}, nil, 1},
+		{"recovers from 503s", []int{503, 503, 200}, nil, 3},
+		{"gives up after MaxFetchAttempts", []int{503}, errUpstreamUnavailable, MaxFetchAttempts},
+		{"does not retry 400", []int{400, 200}, errUpstreamUnavailable, 1},
+		{"does not retry 429", []int{429, 200}, errUpstreamUnavailable, 1},
+		{"does not retry 404", []int{404, 200}, errPassageNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newStatusServer(t, body, tt.statuses...)
+
+			var verse BibleVerse
+			err := fetchFromAPI(context.Background(), srv.URL, &verse)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("fetchFromAPI() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", got, tt.wantRequests)
+			}
+			if tt.wantErr == nil && verse.RandomVerse.Reference() != "John 3:16" {
+				t.Errorf("decoded %q, want John 3:16", verse.RandomVerse.Reference())
+			}
+		})
+	}
+}
+
+func TestFetchFromAPIConcurrencyCap(t *testing.T) {
+	const limit, callers = 3, 10
+	saved := apiRequests
+	apiRequests = newSemaphore(limit)
+	t.Cleanup(func() { apiRequests = saved })
+
+	// Every request reports its arrival, then holds its slot until the test hands it a release
+	var inFlight, peak atomic.Int32
+	arrived := make(chan struct{}, callers)
+	release := make(chan struct{}, callers)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		arrived <- struct{}{}
+		<-release
+		inFlight.Add(-1)
+		fmt.Fprint(w, `{"random_verse": {"text": "Jesus wept."}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var verse BibleVerse
+			errs <- fetchFromAPI(context.Background(), srv.URL, &verse)
+		}()
+	}
+
+	// The first requests take every slot and the rest wait for one
+	for range limit {
+		<-arrived
+	}
+	if got := len(apiRequests); got != limit {
+		t.Errorf("%d request slots taken, want all %d", got, limit)
+	}
+	if got := inFlight.Load(); got != limit {
+		t.Errorf("%d requests in flight with all slots taken, want %d", got, limit)
+	}
+
+	// Each finished request lets exactly one waiting caller through
+	for range callers - limit {
+		release <- struct{}{}
+		<-arrived
+		if got := inFlight.Load(); got > limit {
+			t.Errorf("%d requests in flight, want at most %d", got, limit)
+		}
+	}
+	for range limit {
+		release <- struct{}{}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("fetchFromAPI() error = %v", err)
+		}
+	}
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak of %d requests in flight, want at most %d", got, limit)
+	}
+}
+
+func TestFetchRandomVerseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+		want    error
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			want:    errPassageNotFound,
+		},
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			want:    errUpstreamUnavailable,
+		},
+		{
+			name:    "client error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadRequest) },
+			want:    errUpstreamUnavailable,
+		},
+		{
+			name:    "unparseable body",
+			handler: func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "<html>Bad Gateway</html>") },
+			want:    errParse,
+		},
+		{
+			name: "too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"random_verse": {"text": %q}}`, strings.Repeat("a", DefaultMaxResponseBytes))
+			},
+			want: errResponseTooLarge,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			timeout: 50 * time.Millisecond,
+			want:    errTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			t.Cleanup(srv.Close)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			if _, err := fetchRandomVerse(ctx, srv.URL); !errors.Is(err, tt.want) {
+				t.Errorf("fetchRandomVerse() error = %v, want errors.Is %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchRandomVerseUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	if _, err := fetchRandomVerse(context.Background(), srv.URL); !errors.Is(err, errUpstreamUnavailable) {
+		t.Errorf("fetchRandomVerse() error = %v, want errors.Is %v", err, errUpstreamUnavailable)
+	}
+}
+
+func TestGetRandomVerseFromBookUnknownBook(t *testing.T) {
+	_, err := getRandomVerseFromBook(context.Background(), "Psalmz", "web")
+
+	var bookErr *unknownBookError
+	if !errors.As(err, &bookErr) {
+		t.Fatalf("getRandomVerseFromBook() error = %v, want an *unknownBookError", err)
+	}
+	if bookErr.Name != "Psalmz" || bookErr.Suggestion != "Psalms" {
+		t.Errorf("unknownBookError = %+v, want Psalmz with a suggestion of Psalms", bookErr)
+	}
+}
+
+func TestFetchRandomVerseMalformedJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"empty body", "", errParse},
+		{"not JSON", "Service Unavailable", errParse},
+		{"truncated", `{"random_verse": {"text": "For God so`, errParse},
+		{"wrong shape", `["John 3:16"]`, errParse},
+		{"empty object", "{}", errEmptyVerse},
+		{"error payload", `{"error": "translation not found"}`, errEmptyVerse},
+		{"null verse", `{"random_verse": null}`, errEmptyVerse},
+		{"blank text", `{"random_verse": {"book": "John", "chapter": 3, "verse": 16, "text": " \n"}}`, errEmptyVerse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newStatusServer(t, tt.body, http.StatusOK)
+
+			verse, err := fetchRandomVerse(context.Background(), srv.URL)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("fetchRandomVerse() = %v, %v, want errors.Is %v", verse, err, tt.want)
+			}
+
+			// Users get the generic apology rather than a blank embed
+			ctx := context.Background()
+			if got, want := verseErrorReply(ctx, err), localize(ctx, "verse_unavailable"); got != want {
+				t.Errorf("reply = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// fixtureTransport answers every request with the contents of a file in testdata
+type fixtureTransport string
+
+func (f fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := os.ReadFile(filepath.Join("testdata", string(f)))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetVerseByReferenceAcrossChapters(t *testing.T) {
+	saved := httpClient
+	httpClient = &http.Client{Transport: fixtureTransport("john_3_16-4_2.json")}
+	t.Cleanup(func() { httpClient = saved })
+
+	verse, err := getVerseByReference(context.Background(), "John 3:16-4:2", "web")
+	if err != nil {
+		t.Fatalf("getVerseByReference() error = %v", err)
+	}
+	if got := verse.Reference; got != "John 3:16-4:2" {
+		t.Errorf("Reference = %q, want John 3:16-4:2", got)
+	}
+	if len(verse.Verses) != 4 {
+		t.Fatalf("got %d verses, want 4", len(verse.Verses))
+	}
+
+	text := verse.Text()
+	if strings.Count(text, "**Chapter") != 1 || !strings.Contains(text, "**Chapter 4**") {
+		t.Errorf("Text() = %q, want a single Chapter 4 marker", text)
+	}
+	// The marker sits between the last verse of chapter 3 and the first of chapter 4
+	if before, after, ok := strings.Cut(text, "**Chapter 4**"); !ok || !strings.Contains(before, "**17**") || !strings.HasPrefix(strings.TrimSpace(after), "**1**") {
+		t.Errorf("Text() = %q, want the marker between verses 3:17 and 4:1", text)
+	}
+}
+
+// bollsChapters serves bolls.life chapter text keyed by request path
+type bollsChapters map[string]string
+
+func (c bollsChapters) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := c[req.URL.Path]
+	if !ok {
+		body = "[]"
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestBollsByReferenceAcrossChapters(t *testing.T) {
+	saved := httpClient
+	httpClient = &http.Client{Transport: bollsChapters{
+		"/get-text/WEB/43/3/": `[{"verse":15,"text":"a"},{"verse":16,"text":"b"},{"verse":17,"text":"c"},{"verse":18,"text":"d"}]`,
+		"/get-text/WEB/43/4/": `[{"verse":1,"text":"e"},{"verse":2,"text":"f"},{"verse":3,"text":"g"}]`,
+	}}
+	t.Cleanup(func() { httpClient = saved })
+
+	verse, err := bollsProvider{}.ByReference(context.Background(), "John 3:16-4:2", "web")
+	if err != nil {
+		t.Fatalf("ByReference() error = %v", err)
+	}
+
+	var got []string
+	for _, v := range verse.Verses {
+		got = append(got, fmt.Sprintf("%d:%d", v.Chapter, v.Verse))
+	}
+	if want := []string{"3:16", "3:17", "3:18", "4:1", "4:2"}; !slices.Equal(got, want) {
+		t.Errorf("verses = %v, want %v", got, want)
+	}
+}
+
+func TestLimitVersesNote(t *testing.T) {
+	passage := &BibleVerse{Reference: "John 3:16-18"}
+	for v := 16; v <= 18; v++ {
+		passage.Verses = append(passage.Verses, RandomVerse{Book: "John", Chapter: 3, Verse: v, Text: fmt.Sprintf("verse %d", v)})
+	}
+	passage.RandomVerse = passage.Verses[0]
+
+	tests := []struct {
+		n        int
+		wantNote string
+	}{
+		{n: 1, wantNote: "*(showing the first verse)*"},
+		{n: 2, wantNote: "*(showing first 2 verses)*"},
+		{n: 3},
+		{n: 20},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			text := passage.limitVerses(tt.n).Text()
+			if tt.wantNote == "" {
+				if strings.Contains(text, "*(showing") {
+					t.Errorf("Text() = %q, want no note when nothing was dropped", text)
+				}
+				return
+			}
+			if !strings.HasSuffix(text, "\n\n"+tt.wantNote) {
+				t.Errorf("Text() = %q, want it to end with %q", text, tt.wantNote)
+			}
+		})
+	}
+	if passage.Truncated {
+		t.Error("limitVerses() marked the original passage truncated")
+	}
+}
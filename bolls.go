@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bollsTranslations maps bible-api.com identifiers to the bolls.life translations that match them
+var bollsTranslations = map[string]Translation{
+	"kjv": {Identifier: "KJV", Name: "King James Version"},
+	"web": {Identifier: "WEB", Name: "World English Bible"},
+	"ylt": {Identifier: "YLT", Name: "Young's Literal Translation"},
+}
+
+// bollsVerse is a single verse as returned by the bolls.life text endpoints
+type bollsVerse struct {
+	Book    int    `json:"book"`
+	Chapter int    `json:"chapter"`
+	Verse   int    `json:"verse"`
+	Text    string `json:"text"`
+}
+
+// bollsBook is a book entry from the bolls.life book list
+type bollsBook struct {
+	BookID   int `json:"bookid"`
+	Chapters int `json:"chapters"`
+}
+
+// strongsNumber matches the Strong's concordance markers some bolls.life translations embed
+var strongsNumber = regexp.MustCompile(`<S>\d+</S>`)
+
+// referencePattern splits a reference such as "John 3:16-18" or "John 3:16-4:2" into book, chapter,
+// first verse, and the chapter and verse the range ends at
+var referencePattern = regexp.MustCompile(`^(.+?)\s+(\d+)(?::(\d+)(?:-(?:(\d+):)?(\d+))?)?$`)
+
+// bollsProvider is the VerseProvider backed by bolls.life, used when bible-api.com is unavailable.
+// It only knows the translations in bollsTranslations.
+type bollsProvider struct{}
+
+func (bollsProvider) Random(ctx context.Context, translation string) (*BibleVerse, error) {
+	t, ok := bollsTranslations[translation]
+	if !ok {
+		return nil, errUnknownTranslation
+	}
+
+	var v bollsVerse
+	endpoint := fmt.Sprintf("%s/get-random-verse/%s/", SearchAPIBase, t.Identifier)
+	if err := fetchFromAPI(ctx, endpoint, &v); err != nil {
+		return nil, err
+	}
+	return normalizeBollsVerses(translation, t, []bollsVerse{v}, "")
+}
+
+func (bollsProvider) RandomFromBook(ctx context.Context, bookName, translation string) (*BibleVerse, error) {
+	t, ok := bollsTranslations[translation]
+	if !ok {
+		return nil, errUnknownTranslation
+	}
+	book, ok := findBook(bookName)
+	if !ok {
+		return nil, &unknownBookError{Name: bookName}
+	}
+
+	// bolls.life has no random-from-book endpoint, so pick a chapter and then a verse ourselves
+	var books []bollsBook
+	if err := fetchFromAPI(ctx, fmt.Sprintf("%s/get-books/%s/", SearchAPIBase, t.Identifier), &books); err != nil {
+		return nil, err
+	}
+	chapters := 0
+	for _, b := range books {
+		if b.BookID == bookNumber(book) {
+			chapters = b.Chapters
+		}
+	}
+	if chapters == 0 {
+		return nil, errPassageNotFound
+	}
+
+	verses, err := fetchBollsChapter(ctx, t, bookNumber(book), rand.Intn(chapters)+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(verses) == 0 {
+		return nil, errEmptyVerse
+	}
+	return normalizeBollsVerses(translation, t, []bollsVerse{verses[rand.Intn(len(verses))]}, "")
+}
+
+// RandomFromBooks picks one of the books first, so short books come up as often as long ones
+func (p bollsProvider) RandomFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error) {
+	if len(bookIDs) == 0 {
+		return nil, errPassageNotFound
+	}
+	return p.RandomFromBook(ctx, bookIDs[rand.Intn(len(bookIDs))], translation)
+}
+
+func (bollsProvider) ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	t, ok := bollsTranslations[translation]
+	if !ok {
+		return nil, errUnknownTranslation
+	}
+
+	ref = strings.TrimSpace(ref)
+	match := referencePattern.FindStringSubmatch(ref)
+	if match == nil {
+		return nil, errPassageNotFound
+	}
+	book, ok := findBook(match[1])
+	if !ok {
+		return nil, errPassageNotFound
+	}
+	chapter, _ := strconv.Atoi(match[2])
+
+	// A bare chapter reference returns the whole chapter; otherwise keep the verses from
+	// chapter:first to lastChapter:last, which may span chapters
+	first, last, lastChapter := 1, math.MaxInt, chapter
+	if match[3] != "" {
+		first, _ = strconv.Atoi(match[3])
+		last = first
+	}
+	if match[4] != "" {
+		lastChapter, _ = strconv.Atoi(match[4])
+	}
+	if match[5] != "" {
+		last, _ = strconv.Atoi(match[5])
+	}
+	if lastChapter < chapter {
+		return nil, errPassageNotFound
+	}
+
+	var verses []bollsVerse
+	for c := chapter; c <= lastChapter; c++ {
+		chapterVerses, err := fetchBollsChapter(ctx, t, bookNumber(book), c)
+		if err != nil {
+			return nil, err
+		}
+		// A chapter past the end of the book comes back empty
+		if len(chapterVerses) == 0 {
+			break
+		}
+		for _, v := range chapterVerses {
+			if (c > chapter || v.Verse >= first) && (c < lastChapter || v.Verse <= last) {
+				verses = append(verses, v)
+			}
+		}
+	}
+	if len(verses) == 0 {
+		return nil, errPassageNotFound
+	}
+
+	return normalizeBollsVerses(translation, t, verses, fmt.Sprintf("%s %s", book.Name, strings.TrimSpace(ref[len(match[1]):])))
+}
+
+// fetchBollsChapter fetches every verse of a chapter
+func fetchBollsChapter(ctx context.Context, t Translation, book, chapter int) ([]bollsVerse, error) {
+	var verses []bollsVerse
+	endpoint := fmt.Sprintf("%s/get-text/%s/%d/%d/", SearchAPIBase, url.PathEscape(t.Identifier), book, chapter)
+	if err := fetchFromAPI(ctx, endpoint, &verses); err != nil {
+		return nil, err
+	}
+
+	// The chapter endpoint leaves book and chapter out of each verse
+	for i := range verses {
+		verses[i].Book = book
+		verses[i].Chapter = chapter
+	}
+	return verses, nil
+}
+
+// normalizeBollsVerses maps bolls.life verses into the same BibleVerse shape bible-api.com produces
+func normalizeBollsVerses(translation string, t Translation, verses []bollsVerse, reference string) (*BibleVerse, error) {
+	verse := &BibleVerse{
+		Translation: Translation{Identifier: translation, Name: t.Name},
+		Reference:   reference,
+	}
+	for _, v := range verses {
+		book, ok := bookByNumber(v.Book)
+		if !ok {
+			continue
+		}
+		text := strings.TrimSpace(htmlTag.ReplaceAllString(strongsNumber.ReplaceAllString(v.Text, ""), ""))
+		verse.Verses = append(verse.Verses, RandomVerse{
+			BookID:  book.ID,
+			Book:    book.Name,
+			Chapter: v.Chapter,
+			Verse:   v.Verse,
+			Text:    text,
+		})
+	}
+
+	if len(verse.Verses) == 0 || verse.Verses[0].Text == "" {
+		botMetrics.observeAPIError("empty")
+		return nil, errEmptyVerse
+	}
+	verse.RandomVerse = verse.Verses[0]
+	return verse, nil
+}
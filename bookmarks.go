@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Bookmark is a verse a user has saved
+type Bookmark struct {
+	Reference   string    `json:"reference"`
+	Translation string    `json:"translation"`
+	SavedAt     time.Time `json:"saved_at"`
+}
+
+// BookmarkStore persists bookmarks per Discord user ID
+type BookmarkStore interface {
+	// Add saves a bookmark for the user, reporting false if it was already saved
+	Add(userID string, bookmark Bookmark) (bool, error)
+	// List returns the user's bookmarks, oldest first
+	List(userID string) ([]Bookmark, error)
+}
+
+// jsonBookmarkStore keeps bookmarks in memory and mirrors them to a JSON file
+type jsonBookmarkStore struct {
+	mu     sync.Mutex
+	path   string
+	byUser map[string][]Bookmark
+}
+
+// newJSONBookmarkStore loads the bookmarks saved at path, starting empty if the file does not exist
+func newJSONBookmarkStore(path string) (*jsonBookmarkStore, error) {
+	store := &jsonBookmarkStore{
+		path:   path,
+		byUser: make(map[string][]Bookmark),
+	}
+	if err := readJSONFile(path, &store.byUser); err != nil {
+		return nil, fmt.Errorf("cannot load bookmarks: %w", err)
+	}
+	return store, nil
+}
+
+func (js *jsonBookmarkStore) Add(userID string, bookmark Bookmark) (bool, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	for _, existing := range js.byUser[userID] {
+		if existing.Reference == bookmark.Reference && existing.Translation == bookmark.Translation {
+			return false, nil
+		}
+	}
+
+	js.byUser[userID] = append(js.byUser[userID], bookmark)
+	if err := writeJSONFile(js.path, js.byUser); err != nil {
+		return false, fmt.Errorf("cannot save bookmarks: %w", err)
+	}
+	return true, nil
+}
+
+func (js *jsonBookmarkStore) List(userID string) ([]Bookmark, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	return append([]Bookmark(nil), js.byUser[userID]...), nil
+}
+
+// readJSONFile decodes the JSON file at path into v, leaving v untouched if the file does not exist
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile atomically replaces the file at path with v encoded as JSON
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// recentVerses remembers the last verse shown to each user so it can be bookmarked
+type recentVerses struct {
+	mu     sync.Mutex
+	byUser map[string]*BibleVerse
+}
+
+// newRecentVerses creates an empty tracker
+func newRecentVerses() *recentVerses {
+	return &recentVerses{byUser: make(map[string]*BibleVerse)}
+}
+
+// Remember records verse as the last one shown to userID
+func (r *recentVerses) Remember(userID string, verse *BibleVerse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUser[userID] = verse
+}
+
+// Last returns the last verse shown to userID
+func (r *recentVerses) Last(userID string) (*BibleVerse, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	verse, ok := r.byUser[userID]
+	return verse, ok
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// BooksPerPage is how many books each page of !books lists
+const BooksPerPage = 22
+
+// Book is a book of the Bible with the 3-letter ID used by the Bible API
+type Book struct {
+	ID   string
+	Name string
+}
+
+// Books lists the 66 books of the Protestant canon in order
+var Books = []Book{
+	{"GEN", "Genesis"}, {"EXO", "Exodus"}, {"LEV", "Leviticus"}, {"NUM", "Numbers"},
+	{"DEU", "Deuteronomy"}, {"JOS", "Joshua"}, {"JDG", "Judges"}, {"RUT", "Ruth"},
+	{"1SA", "1 Samuel"}, {"2SA", "2 Samuel"}, {"1KI", "1 Kings"}, {"2KI", "2 Kings"},
+	{"1CH", "1 Chronicles"}, {"2CH", "2 Chronicles"}, {"EZR", "Ezra"}, {"NEH", "Nehemiah"},
+	{"EST", "Esther"}, {"JOB", "Job"}, {"PSA", "Psalms"}, {"PRO", "Proverbs"},
+	{"ECC", "Ecclesiastes"}, {"SNG", "Song of Solomon"}, {"ISA", "Isaiah"}, {"JER", "Jeremiah"},
+	{"LAM", "Lamentations"}, {"EZK", "Ezekiel"}, {"DAN", "Daniel"}, {"HOS", "Hosea"},
+	{"JOL", "Joel"}, {"AMO", "Amos"}, {"OBA", "Obadiah"}, {"JON", "Jonah"},
+	{"MIC", "Micah"}, {"NAM", "Nahum"}, {"HAB", "Habakkuk"}, {"ZEP", "Zephaniah"},
+	{"HAG", "Haggai"}, {"ZEC", "Zechariah"}, {"MAL", "Malachi"},
+	{"MAT", "Matthew"}, {"MRK", "Mark"}, {"LUK", "Luke"}, {"JHN", "John"},
+	{"ACT", "Acts"}, {"ROM", "Romans"}, {"1CO", "1 Corinthians"}, {"2CO", "2 Corinthians"},
+	{"GAL", "Galatians"}, {"EPH", "Ephesians"}, {"PHP", "Philippians"}, {"COL", "Colossians"},
+	{"1TH", "1 Thessalonians"}, {"2TH", "2 Thessalonians"}, {"1TI", "1 Timothy"}, {"2TI", "2 Timothy"},
+	{"TIT", "Titus"}, {"PHM", "Philemon"}, {"HEB", "Hebrews"}, {"JAS", "James"},
+	{"1PE", "1 Peter"}, {"2PE", "2 Peter"}, {"1JN", "1 John"}, {"2JN", "2 John"},
+	{"3JN", "3 John"}, {"JUD", "Jude"}, {"REV", "Revelation"},
+}
+
+// bookGroups maps the shortcuts accepted by !verse to the IDs of the books they draw from
+var bookGroups = map[string][]string{
+	"ot":      bookIDs(Books[:39]),
+	"nt":      bookIDs(Books[39:]),
+	"gospels": {"MAT", "MRK", "LUK", "JHN"},
+}
+
+// bookIDs lists the IDs of books in order
+func bookIDs(books []Book) []string {
+	ids := make([]string, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	return ids
+}
+
+// bookByNumber returns the book at its 1-based canonical position
+func bookByNumber(n int) (Book, bool) {
+	if n < 1 || n > len(Books) {
+		return Book{}, false
+	}
+	return Books[n-1], true
+}
+
+// bookNumber returns the 1-based position of book in the canon, as used by bolls.life
+func bookNumber(book Book) int {
+	for i, b := range Books {
+		if b.ID == book.ID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// findBook looks up a book by name or ID, ignoring case and spacing
+func findBook(name string) (Book, bool) {
+	key := normalizeBookName(name)
+	for _, book := range Books {
+		if normalizeBookName(book.Name) == key || strings.EqualFold(book.ID, key) {
+			return book, true
+		}
+	}
+	return Book{}, false
+}
+
+// suggestBook returns the book whose name is closest to name, if any is reasonably close
+func suggestBook(name string) (Book, bool) {
+	key := normalizeBookName(name)
+	best, bestDistance := Book{}, -1
+	for _, book := range Books {
+		d := levenshtein(key, normalizeBookName(book.Name))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = book, d
+		}
+	}
+
+	// Only suggest when less than half the name would need to change
+	if bestDistance < 0 || bestDistance*2 > len(key) {
+		return Book{}, false
+	}
+	return best, true
+}
+
+// normalizeBookName lowercases a book name and drops spaces so "1 John" matches "1john"
+func normalizeBookName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// bookPages lists every book with its ID, BooksPerPage to a page
+func bookPages() []string {
+	var pages []string
+	for start := 0; start < len(Books); start += BooksPerPage {
+		var page strings.Builder
+		for _, book := range Books[start:min(start+BooksPerPage, len(Books))] {
+			fmt.Fprintf(&page, "`%s` %s\n", book.ID, book.Name)
+		}
+		pages = append(pages, strings.TrimRight(page.String(), "\n"))
+	}
+	return pages
+}
+
+// handleBooks lists the books of the Bible and their IDs, with buttons to page through them
+func (b *Bot) handleBooks(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	p := &paginator{
+		pages: bookPages(),
+		render: func(text string, page, total int) *discordgo.MessageEmbed {
+			return &discordgo.MessageEmbed{
+				Title:       localize(ctx, "books_title"),
+				Description: text,
+				Color:       b.config.Load().EmbedColor,
+				Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d/%d", page, total)},
+			}
+		},
+	}
+
+	msg, err := b.replyMessage(ctx, s, m, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{b.pageEmbed(p)},
+		Components: p.components(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Embed reply error", "channel_id", m.ChannelID, "user_id", m.Author.ID, "error", err)
+		return
+	}
+	b.paginators.Add(msg.ID, p)
+}
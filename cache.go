@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry is a cached verse together with its expiry time
+type cacheEntry struct {
+	verse   *BibleVerse
+	expires time.Time
+}
+
+// verseCache is a small TTL cache of fetched verses. A zero TTL disables caching.
+type verseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+// newVerseCache creates a cache whose entries live for ttl
+func newVerseCache(ttl time.Duration) *verseCache {
+	return &verseCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the cached verse for key if it has not expired
+func (c *verseCache) Get(key string) (*BibleVerse, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.verse, true
+}
+
+// Set stores a verse under key and drops any expired entries
+func (c *verseCache) Set(key string, verse *BibleVerse) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{verse: verse, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Verse card geometry, in pixels. Text is tried at sizes from CardMaxFontSize down to
+// CardMinFontSize; if it still does not fit at the smallest size it is truncated.
+const (
+	CardWidth       = 1200
+	CardHeight      = 675
+	CardMargin      = 80
+	CardMaxFontSize = 56
+	CardMinFontSize = 24
+	CardFontStep    = 4
+	CardRefFontSize = 36
+	CardFileName    = "verse.png"
+)
+
+// cardFonts parses the embedded Go fonts once, on first use
+var cardFonts = sync.OnceValues(func() ([2]*opentype.Font, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	return [2]*opentype.Font{regular, bold}, nil
+})
+
+// renderVerseCard draws the verse text and its reference over a background shaded from
+// accent, returning the card as PNG
+func renderVerseCard(verse *BibleVerse, accent int) ([]byte, error) {
+	fonts, err := cardFonts()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load card fonts: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	drawGradient(img, accent)
+
+	refFace, err := opentype.NewFace(fonts[1], &opentype.FaceOptions{Size: CardRefFontSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer refFace.Close()
+
+	// The reference sits along the bottom; the verse fills the space above it
+	reference := fmt.Sprintf("%s (%s)", verse.Title(), strings.ToUpper(verse.Translation.Identifier))
+	refHeight := refFace.Metrics().Height.Ceil()
+	drawLine(img, refFace, reference, CardHeight-CardMargin)
+
+	text := strings.ReplaceAll(verse.Text(), "**", "")
+	maxWidth := CardWidth - 2*CardMargin
+	maxHeight := CardHeight - 2*CardMargin - 2*refHeight
+
+	for size := float64(CardMaxFontSize); size >= CardMinFontSize; size -= CardFontStep {
+		face, err := opentype.NewFace(fonts[0], &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+
+		lineHeight := face.Metrics().Height.Ceil()
+		lines := wrapText(face, text, maxWidth)
+		maxLines := maxHeight / lineHeight
+		if len(lines) > maxLines && size-CardFontStep >= CardMinFontSize {
+			face.Close()
+			continue
+		}
+
+		// Still too long at the smallest size, so cut it off
+		if len(lines) > maxLines {
+			lines = lines[:maxLines]
+			words := strings.Fields(lines[maxLines-1])
+			if len(words) > 1 {
+				words = words[:len(words)-1]
+			}
+			lines[maxLines-1] = strings.Join(words, " ") + "…"
+		}
+
+		top := CardMargin + (maxHeight-len(lines)*lineHeight)/2 + face.Metrics().Ascent.Ceil()
+		for i, line := range lines {
+			drawLine(img, face, line, top+i*lineHeight)
+		}
+		face.Close()
+		break
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("cannot encode verse card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawGradient fills img with accent fading to near black from top to bottom
+func drawGradient(img *image.RGBA, accent int) {
+	r, g, b := float64(accent>>16&0xff), float64(accent>>8&0xff), float64(accent&0xff)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		// Darken towards the bottom so white text stays readable on light accents
+		shade := 0.6 - 0.5*float64(y)/float64(bounds.Dy())
+		c := color.RGBA{R: uint8(r * shade), G: uint8(g * shade), B: uint8(b * shade), A: 0xff}
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine draws one line of white text centred horizontally with its baseline at y
+func drawLine(img *image.RGBA, face font.Face, text string, y int) {
+	d := font.Drawer{Dst: img, Src: image.White, Face: face}
+	x := (CardWidth - d.MeasureString(text).Ceil()) / 2
+	d.Dot = fixed.P(x, y)
+	d.DrawString(text)
+}
+
+// wrapText breaks text into lines no wider than maxWidth pixels, cutting between words
+func wrapText(face font.Face, text string, maxWidth int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > maxWidth {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,930 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"golang.org/x/sync/singleflight"
+)
+
+// CommandHandler runs a command with the arguments that followed its name.
+// ctx is cancelled on shutdown or when the command exceeds COMMAND_TIMEOUT.
+type CommandHandler func(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string)
+
+// Command describes a single bot command
+type Command struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+	Handler     CommandHandler
+
+	// OwnerOnly commands may only be run by OWNER_ID. Others with a Permission, such as
+	// discordgo.PermissionManageServer, also by members who have it in the channel.
+	OwnerOnly  bool
+	Permission int64
+
+	// EphemeralErrors shows the slash command's errors and cooldown notices only to the user who ran it
+	EphemeralErrors bool
+}
+
+// Bot holds the configuration and command set shared by all event handlers
+type Bot struct {
+	// config is swapped wholesale by !reload, so read it once per use with Load
+	config atomic.Pointer[AppConfig]
+
+	// verses is where verse commands get their content
+	verses VerseProvider
+
+	// bookmarks persists saved verses; recent tracks what each user saw last
+	bookmarks BookmarkStore
+	recent    *recentVerses
+
+	// settings holds what each guild customised, such as its prefix and translation, and each user's reading streak
+	settings SettingsStore
+
+	// subscriptions lists the users who get the daily verse by DM
+	subscriptions SubscriptionStore
+
+	// ctx is cancelled when the bot begins shutting down
+	ctx context.Context
+
+	// inFlight tracks running command handlers so shutdown can drain them
+	inFlight sync.WaitGroup
+	active   atomic.Int32
+
+	// usage counts commands run since startup for !stats
+	usage commandUsage
+
+	// audit records every command run when AUDIT_LOG_FILE is set
+	audit *auditLog
+
+	// reloaded and autoVerseReloaded tell the daily and auto verse schedulers the configuration has been swapped
+	reloaded          chan struct{}
+	autoVerseReloaded chan struct{}
+
+	// maintenance is set by !maintenance to refuse everything but admin commands, until a restart
+	maintenance atomic.Bool
+
+	// ready is set once Discord has sent the Ready event
+	ready atomic.Bool
+
+	// statusIndex picks the BOT_STATUS entry shown, advanced by rotatePresence
+	statusIndex atomic.Int64
+
+	// guilds holds the IDs of guilds already logged as connected, since Discord
+	// sends GuildCreate again for each of them after a reconnect
+	guilds sync.Map
+
+	// Short-lived caches for random verses (keyed by translation) and passage lookups
+	randomCache    *verseCache
+	referenceCache *verseCache
+
+	// referenceLookups collapses concurrent fetches of the same passage; random verses are
+	// never shared since each request should get a different one
+	referenceLookups singleflight.Group
+
+	// translations caches the translation list shown by !translations
+	translations *translationList
+
+	// verseLimiter throttles verse requests per user; feedbackLimiter keeps !feedback from being used to spam
+	verseLimiter    *rateLimiter
+	feedbackLimiter *rateLimiter
+
+	// paginators tracks page navigation state for long passages
+	paginators *paginatorStore
+
+	// posted maps the IDs of verse messages the bot sent to their verse, for ⭐ bookmarks
+	posted *verseCache
+
+	// sends serializes bulk posts so they honor Discord's rate limits
+	sends *sendQueue
+
+	// daily holds the verse of the day shared by !dailyverse and the scheduler;
+	// dailyDebounce keeps the two from double-posting it to a channel
+	daily         *dailyVerses
+	dailyDebounce *channelDebounce
+
+	// handledMessages remembers which message IDs have run a command so edits cannot repeat it
+	handledMessages *channelDebounce
+
+	// commands is the single source of truth for both the dispatcher and !help;
+	// byName indexes it by every name and alias
+	commands []Command
+	byName   map[string]*Command
+}
+
+// newBot creates a Bot and registers its commands
+func newBot(ctx context.Context, config *AppConfig, verses VerseProvider, bookmarks BookmarkStore, settings SettingsStore, subscriptions SubscriptionStore, audit *auditLog) *Bot {
+	b := &Bot{
+		subscriptions:     subscriptions,
+		audit:             audit,
+		verses:            verses,
+		bookmarks:         bookmarks,
+		recent:            newRecentVerses(),
+		settings:          settings,
+		ctx:               ctx,
+		randomCache:       newVerseCache(config.VerseCacheTTL),
+		referenceCache:    newVerseCache(config.ReferenceCacheTTL),
+		translations:      newTranslationList(TranslationListTTL),
+		verseLimiter:      newRateLimiter(config.VerseRateLimit, config.VerseRateWindow),
+		feedbackLimiter:   newRateLimiter(FeedbackRateLimit, FeedbackRateWindow),
+		paginators:        newPaginatorStore(PaginatorTTL),
+		posted:            newVerseCache(PostedVerseTTL),
+		sends:             newSendQueue(SendQueueSize),
+		dailyDebounce:     newChannelDebounce(config.DailyVerseDebounce),
+		handledMessages:   newChannelDebounce(EditedCommandWindow),
+		reloaded:          make(chan struct{}, 1),
+		autoVerseReloaded: make(chan struct{}, 1),
+	}
+	b.config.Store(config)
+	b.daily = newDailyVerses(func() *time.Location { return b.config.Load().DailyVerseLocation })
+	go b.verseLimiter.cleanupLoop(ctx)
+	go b.feedbackLimiter.cleanupLoop(ctx)
+	go b.paginators.cleanupLoop(ctx)
+	go b.sends.run(ctx)
+
+	b.commands = []Command{
+		{Name: "hello", Description: "Say hello to the bot", Handler: b.handleHello},
+		{Name: "ping", Aliases: []string{"p"}, Description: "Check that the bot is responsive and show its latency", Handler: b.handlePing},
+		{Name: "verse", Aliases: []string{"v"}, Usage: "[translation] [reference|theme|nt|ot|gospels] [--audio] [--json]", Description: "Get a random verse, look up a passage like John 3:16, pick one for a theme like hope, or draw from the New Testament, Old Testament or gospels", Handler: b.handleVerse, EphemeralErrors: true},
+		{Name: "dailyverse", Description: "Get today's verse, the same for everyone until midnight", Handler: b.handleDailyVerse},
+		{Name: "random", Usage: "<book>", Description: "Get a random verse from a specific book, e.g. Psalms", Handler: b.handleRandom},
+		{Name: "books", Description: "List the books of the Bible with the IDs used in references", Handler: b.handleBooks},
+		{Name: "compare", Usage: "<reference> <translation> <translation>...", Description: "Show a passage in several translations side by side", Handler: b.handleCompare},
+		{Name: "translation", Usage: "[translation]", Description: "Show the default translation for this server, or set it (admins only)", Handler: b.handleTranslation},
+		{Name: "translations", Description: "List the translations you can choose from", Handler: b.handleTranslations},
+		{Name: "dailychannel", Usage: "[off]", Description: "Post the daily verse in this channel, or stop posting it", Handler: b.handleDailyChannel, Permission: discordgo.PermissionManageServer},
+		{Name: "mention", Usage: "<on|off>", Description: "Choose whether verse replies ping the person who asked", Handler: b.handleMention, Permission: discordgo.PermissionManageServer},
+		{Name: "style", Usage: "[embed|plain|image]", Description: "Show or change how verses are displayed on this server", Handler: b.handleStyle},
+		{Name: "setprefix", Usage: "<prefix>", Description: "Change the command prefix for this server", Handler: b.handleSetPrefix, Permission: discordgo.PermissionManageServer},
+		{Name: "disable", Usage: "<command>", Description: "Turn a command off in this server", Handler: b.handleDisable, Permission: discordgo.PermissionManageServer},
+		{Name: "enable", Usage: "<command>", Description: "Turn a disabled command back on in this server", Handler: b.handleEnable, Permission: discordgo.PermissionManageServer},
+		{Name: "bookmark", Description: "Save the last verse you were shown", Handler: b.handleBookmark},
+		{Name: "bookmarks", Description: "List your saved verses", Handler: b.handleBookmarks},
+		{Name: "subscribe", Description: "Get the daily verse by DM every day", Handler: b.handleSubscribe},
+		{Name: "unsubscribe", Description: "Stop getting the daily verse by DM", Handler: b.handleUnsubscribe},
+		{Name: "streak", Description: "Show how many days in a row you've read a verse", Handler: b.handleStreak},
+		{Name: "search", Usage: "<keyword>", Description: "Find verses containing a keyword", Handler: b.handleSearch},
+		{Name: "feedback", Usage: "<message>", Description: "Send a suggestion or problem report to the bot's maintainers", Handler: b.handleFeedback},
+		{Name: "version", Aliases: []string{"about"}, Description: "Show which build of the bot is running", Handler: b.handleVersion},
+		{Name: "help", Description: "List all available commands", Handler: b.handleHelp},
+		{Name: "reload", Description: "Reload configuration from the environment", Handler: b.handleReload, OwnerOnly: true},
+		{Name: "stats", Description: "Show uptime and server count", Handler: b.handleStats, OwnerOnly: true},
+		{Name: "maintenance", Usage: "<on|off>", Description: "Turn maintenance mode on or off, refusing all but admin commands while on", Handler: b.handleMaintenance, OwnerOnly: true},
+	}
+	b.byName = indexCommands(b.commands)
+	return b
+}
+
+// indexCommands maps every command name and alias to its command. A name claimed twice
+// is a programming mistake, so it panics at startup rather than shadowing a command.
+func indexCommands(commands []Command) map[string]*Command {
+	byName := make(map[string]*Command)
+	for i := range commands {
+		c := &commands[i]
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			if other, ok := byName[name]; ok {
+				panic(fmt.Sprintf("command name %q is used by both %s and %s", name, other.Name, c.Name))
+			}
+			byName[name] = c
+		}
+	}
+	return byName
+}
+
+// findCommand looks up a command by name or alias
+func (b *Bot) findCommand(name string) (*Command, bool) {
+	c, ok := b.byName[name]
+	return c, ok
+}
+
+// messageCreate handles incoming Discord messages dynamically using message context
+func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	defer b.recoverPanic(s, m.ChannelID)
+
+	// Ignore messages from the bot itself
+	if m.Author.ID == s.State.User.ID {
+		return
+	}
+
+	// Raw message content is personal data, so it is only logged when asked for; commands are logged below either way
+	if b.config.Load().LogMessageContent {
+		slog.Info("Message received", "guild_id", m.GuildID, "channel_id", m.ChannelID, "user_id", m.Author.ID, "username", m.Author.Username, "content", m.Content)
+	}
+
+	// Check if the message starts with this guild's command prefix
+	prefix := b.prefix(m.GuildID)
+	if !strings.HasPrefix(m.Content, prefix) {
+		return
+	}
+
+	// Extract command and arguments
+	content := strings.TrimPrefix(m.Content, prefix)
+	parts := splitArgs(content)
+	if len(parts) == 0 {
+		return
+	}
+
+	// Stop accepting new commands once shutdown has started
+	if b.ctx.Err() != nil {
+		return
+	}
+
+	// A message runs at most once, even when HANDLE_EDITS sees it again after an edit
+	if !b.handledMessages.Allow(m.ID) {
+		return
+	}
+
+	// Ignore commands in channels the bot isn't allowed to answer in, without replying
+	if !b.channelAllowed(m.GuildID, m.ChannelID) {
+		return
+	}
+
+	// Replies use the guild's preferred locale where the catalog supports it, and everything
+	// logged from here on carries the same request ID
+	ctx := withRequestID(withLocale(b.ctx, b.messageLocale(s, m)), newRequestID())
+
+	command, ok := b.findCommand(parts[0])
+	if !ok {
+		// Handle unknown commands
+		b.reply(ctx, s, m, localize(ctx, "unknown_command", prefix))
+		return
+	}
+
+	// Commands turned off globally or by the server are refused before anything else is checked
+	if b.commandDisabled(m.GuildID, command) {
+		b.audit.Record(ctx, m.Author.ID, m.GuildID, m.ChannelID, command.Name, AuditDisabled)
+		b.reply(ctx, s, m, localize(ctx, "command_disabled"))
+		return
+	}
+
+	// During maintenance only admin commands run, and the owner may still try anything
+	if b.inMaintenance(m.Author.ID, command) {
+		b.audit.Record(ctx, m.Author.ID, m.GuildID, m.ChannelID, command.Name, AuditMaintenance)
+		b.reply(ctx, s, m, localize(ctx, "maintenance"))
+		return
+	}
+
+	// Admin commands are refused to everyone without the rights to run them
+	if !b.authorized(s, m, command) {
+		b.audit.Record(ctx, m.Author.ID, m.GuildID, m.ChannelID, command.Name, AuditNotAllowed)
+		b.reply(ctx, s, m, b.deniedReply(ctx, m, command))
+		return
+	}
+
+	defer b.trackHandler()()
+
+	slog.InfoContext(ctx, "Running command", "guild_id", m.GuildID, "channel_id", m.ChannelID, "user_id", m.Author.ID, "command", command.Name)
+	b.countCommand(command.Name)
+
+	// Each command gets one deadline covering every retry and failover, cancelled early if the bot shuts down
+	ctx, cancel := context.WithTimeout(ctx, b.config.Load().CommandTimeout)
+	defer cancel()
+
+	command.Handler(ctx, s, m, parts[1:])
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.WarnContext(ctx, "Command timed out", "guild_id", m.GuildID, "channel_id", m.ChannelID, "command", command.Name)
+		b.audit.Record(ctx, m.Author.ID, m.GuildID, m.ChannelID, command.Name, AuditTimedOut)
+		return
+	}
+	b.audit.Record(ctx, m.Author.ID, m.GuildID, m.ChannelID, command.Name, AuditCompleted)
+}
+
+// messageUpdate runs commands added to a message by editing it, when HANDLE_EDITS is on.
+// Only messages sent within EditedCommandWindow count, and each message runs at most once.
+func (b *Bot) messageUpdate(s *discordgo.Session, u *discordgo.MessageUpdate) {
+	if !b.config.Load().HandleEdits {
+		return
+	}
+
+	// Updates such as link embeds being unfurled arrive without an author or content
+	if u.Message == nil || u.Author == nil || u.Content == "" {
+		return
+	}
+	if time.Since(u.Timestamp) > EditedCommandWindow {
+		return
+	}
+
+	b.messageCreate(s, &discordgo.MessageCreate{Message: u.Message})
+}
+
+// channelAllowed applies the ALLOWED_CHANNELS and DENIED_CHANNELS restrictions.
+// They are meant for server channels, so direct messages are always allowed.
+func (b *Bot) channelAllowed(guildID, channelID string) bool {
+	if guildID == "" {
+		return true
+	}
+
+	config := b.config.Load()
+	if config.DeniedChannels[channelID] {
+		return false
+	}
+	return len(config.AllowedChannels) == 0 || config.AllowedChannels[channelID]
+}
+
+// authorized reports whether the author of m may run command. The owner may run everything.
+func (b *Bot) authorized(s *discordgo.Session, m *discordgo.MessageCreate, command *Command) bool {
+	switch {
+	case b.isOwner(m.Author.ID):
+		return true
+	case command.OwnerOnly:
+		return false
+	case command.Permission != 0:
+		return hasPermission(s, m, command.Permission)
+	default:
+		return true
+	}
+}
+
+// inMaintenance reports whether maintenance mode keeps userID from running command. Admin
+// commands stay available so the bot can still be managed, and the owner is never refused.
+func (b *Bot) inMaintenance(userID string, command *Command) bool {
+	return b.maintenance.Load() && !b.isOwner(userID) && !command.OwnerOnly && command.Permission == 0
+}
+
+// deniedReply explains why authorized refused command
+func (b *Bot) deniedReply(ctx context.Context, m *discordgo.MessageCreate, command *Command) string {
+	switch {
+	case command.OwnerOnly || command.Permission == 0:
+		return localize(ctx, "not_allowed")
+	case m.GuildID == "":
+		return localize(ctx, "guild_only")
+	default:
+		return localize(ctx, "missing_permission", permissionName(command.Permission))
+	}
+}
+
+// permissionNames are the names Discord shows for the permissions commands can require
+var permissionNames = map[int64]string{
+	discordgo.PermissionAdministrator:  "Administrator",
+	discordgo.PermissionManageServer:   "Manage Server",
+	discordgo.PermissionManageChannels: "Manage Channels",
+	discordgo.PermissionManageMessages: "Manage Messages",
+	discordgo.PermissionManageRoles:    "Manage Roles",
+}
+
+// permissionName returns the name Discord shows for permission
+func permissionName(permission int64) string {
+	if name, ok := permissionNames[permission]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", permission)
+}
+
+// hasPermission reports whether the author of m has permission in the channel the message was sent in.
+// Administrators have every permission.
+func hasPermission(s *discordgo.Session, m *discordgo.MessageCreate, permission int64) bool {
+	if m.GuildID == "" {
+		return false
+	}
+
+	// The member may not be cached yet, in which case the member data sent with the message is used
+	perms, err := s.State.UserChannelPermissions(m.Author.ID, m.ChannelID)
+	if err != nil {
+		perms, err = s.State.MessagePermissions(m.Message)
+	}
+	if err != nil {
+		slog.Warn("Cannot resolve member permissions", "guild_id", m.GuildID, "user_id", m.Author.ID, "error", err)
+		return false
+	}
+	return perms&(discordgo.PermissionAdministrator|permission) != 0
+}
+
+// isGuildAdmin reports whether the author of m can manage the server the message was sent in
+func isGuildAdmin(s *discordgo.Session, m *discordgo.MessageCreate) bool {
+	return hasPermission(s, m, discordgo.PermissionManageServer)
+}
+
+// isOwner reports whether userID is the configured bot owner. With no OWNER_ID nobody is.
+func (b *Bot) isOwner(userID string) bool {
+	ownerID := b.config.Load().OwnerID
+	return ownerID != "" && userID == ownerID
+}
+
+// trackHandler marks a command handler as in flight and returns the func that marks it done
+func (b *Bot) trackHandler() func() {
+	b.inFlight.Add(1)
+	b.active.Add(1)
+	return func() {
+		b.active.Add(-1)
+		b.inFlight.Done()
+	}
+}
+
+// recoverPanic keeps a panicking handler from taking the whole bot down. It must be deferred
+// directly by the handler. The panic is logged with its stack, and channelID, when set, is told
+// that something went wrong.
+func (b *Bot) recoverPanic(s *discordgo.Session, channelID string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	slog.Error("Recovered from panic in handler", "panic", r, "channel_id", channelID, "stack", string(debug.Stack()))
+	if channelID != "" {
+		SafeSend(s, channelID, localize(withLocale(b.ctx, b.resolveLocale()), "internal_error"))
+	}
+}
+
+// drain waits up to timeout for in-flight command handlers to finish, reporting whether they all did
+func (b *Bot) drain(timeout time.Duration) bool {
+	slog.Info("Waiting for in-flight command handlers to finish", "active", b.active.Load())
+
+	done := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		slog.Warn("Shutdown timed out with command handlers still running", "active", b.active.Load())
+		return false
+	}
+}
+
+// handleHello greets the user
+func (b *Bot) handleHello(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	// Respond dynamically to the channel the message was received from
+	b.reply(ctx, s, m, b.helloMessage(ctx, m.GuildID))
+}
+
+// helloMessage is the greeting shared by !hello and /hello
+func (b *Bot) helloMessage(ctx context.Context, guildID string) string {
+	return localize(ctx, "hello", b.prefix(guildID))
+}
+
+// handlePing replies to confirm the bot is alive, reporting gateway, message and Bible API latency.
+// The message round trip is only known once the reply is sent, so the reply is edited to add it.
+func (b *Bot) handlePing(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	start := time.Now()
+	msg, err := b.replyMessage(ctx, s, m, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{b.createPingEmbed(ctx, s, 0)},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Embed reply error", "channel_id", m.ChannelID, "user_id", m.Author.ID, "error", err)
+		return
+	}
+
+	if _, err := s.ChannelMessageEditEmbed(msg.ChannelID, msg.ID, b.createPingEmbed(ctx, s, time.Since(start))); err != nil {
+		slog.ErrorContext(ctx, "Error updating ping reply", "channel_id", msg.ChannelID, "error", err)
+	}
+}
+
+// createPingEmbed shows the bot's latencies. A zero roundTrip is shown as still being measured.
+func (b *Bot) createPingEmbed(ctx context.Context, s *discordgo.Session, roundTrip time.Duration) *discordgo.MessageEmbed {
+	roundTripValue := "…"
+	if roundTrip > 0 {
+		roundTripValue = formatLatency(roundTrip)
+	}
+	apiValue := localize(ctx, "ping_no_api")
+	if d := time.Duration(lastAPILatency.Load()); d > 0 {
+		apiValue = formatLatency(d)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: localize(ctx, "pong"),
+		Color: b.config.Load().EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: localize(ctx, "ping_gateway"), Value: formatLatency(s.HeartbeatLatency()), Inline: true},
+			{Name: localize(ctx, "ping_round_trip"), Value: roundTripValue, Inline: true},
+			{Name: localize(ctx, "ping_api"), Value: apiValue, Inline: true},
+		},
+	}
+}
+
+// formatLatency shows a latency in whole milliseconds
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
+
+// handleVerse sends a random verse or the requested passage
+func (b *Bot) handleVerse(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if ok, wait := b.verseLimiter.Allow(m.Author.ID); !ok {
+		b.reply(ctx, s, m, rateLimitReply(ctx, wait))
+		return
+	}
+
+	// --audio anywhere asks for a spoken copy of the verse alongside the text. --json shows the
+	// verse as JSON for debugging, but only to admins and the owner; for anyone else it is ignored.
+	args, audio := takeFlag(args, "--audio")
+	args, debugJSON := takeFlag(args, "--json")
+	debugJSON = debugJSON && (b.isOwner(m.Author.ID) || isGuildAdmin(s, m))
+
+	// An optional leading argument selects the translation, e.g. "!verse kjv John 3:16"
+	translation := ""
+	if len(args) > 0 && isSupportedTranslation(strings.ToLower(args[0])) {
+		translation = args[0]
+		args = args[1:]
+	}
+
+	// A theme such as "!verse hope" stands for a random reference from its list. Book groups
+	// such as "!verse nt" are left for lookupVerse.
+	ref := strings.Join(args, " ")
+	_, group := bookGroups[strings.ToLower(ref)]
+	if themed, ok := themeReference(ref); ok {
+		ref = themed
+	}
+
+	verse, err := b.lookupVerse(ctx, m.GuildID, translation, ref)
+	if err != nil {
+		b.reply(ctx, s, m, verseErrorReply(ctx, err))
+		return
+	}
+
+	// Debug output replaces the usual reply and does not count as reading the verse
+	if debugJSON {
+		send, err := jsonFormatter{}.Format(verse)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error formatting verse as JSON", "reference", verse.Title(), "error", err)
+			b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "verse_unavailable")))
+			return
+		}
+		if _, err := b.replyMessage(ctx, s, m, send); err != nil {
+			slog.ErrorContext(ctx, "Error sending reply", "channel_id", m.ChannelID, "user_id", m.Author.ID, "error", err)
+		}
+		return
+	}
+
+	// Create and send an embedded message with the Bible verse; random ones can be rerolled
+	b.recent.Remember(m.Author.ID, verse)
+	b.recordStreak(m.Author.ID)
+	var extra []discordgo.MessageComponent
+	if len(args) == 0 || group {
+		if translation == "" {
+			translation = b.translation(m.GuildID)
+		}
+		groupName := ""
+		if group {
+			groupName = strings.ToLower(ref)
+		}
+		extra = anotherVerseComponents(ctx, anotherVerseID(strings.ToLower(translation), groupName))
+	}
+
+	send, p := b.verseMessage(m.GuildID, verse)
+	if audio {
+		b.attachVerseAudio(ctx, send, verse)
+	}
+	b.deliverVerse(ctx, s, m, verse, send, p, extra...)
+}
+
+// handleDailyVerse sends the verse of the day
+func (b *Bot) handleDailyVerse(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if !b.dailyDebounce.Allow(m.ChannelID) {
+		slog.InfoContext(ctx, "Skipping daily verse, recently posted in channel", "channel_id", m.ChannelID, "user_id", m.Author.ID)
+		return
+	}
+
+	// A post that never reaches the channel frees it up for a retry
+	verse, err := b.daily.Get(ctx, b.translation(m.GuildID), b.verses.Random)
+	if err != nil {
+		b.dailyDebounce.Release(m.ChannelID)
+		b.reply(ctx, s, m, verseErrorReply(ctx, err))
+		return
+	}
+
+	b.recent.Remember(m.Author.ID, verse)
+	b.recordStreak(m.Author.ID)
+	if !b.sendVerse(ctx, s, m, verse) {
+		b.dailyDebounce.Release(m.ChannelID)
+	}
+}
+
+// handleRandom sends a random verse from the requested book
+func (b *Bot) handleRandom(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		prefix := b.prefix(m.GuildID)
+		b.reply(ctx, s, m, localize(ctx, "usage_random", prefix, prefix))
+		return
+	}
+	if ok, wait := b.verseLimiter.Allow(m.Author.ID); !ok {
+		b.reply(ctx, s, m, rateLimitReply(ctx, wait))
+		return
+	}
+
+	translation := b.translation(m.GuildID)
+	verse, err := b.verses.RandomFromBook(ctx, strings.Join(args, " "), translation)
+	if err != nil {
+		b.reply(ctx, s, m, verseErrorReply(ctx, err))
+		return
+	}
+
+	b.recent.Remember(m.Author.ID, verse)
+	b.sendVerse(ctx, s, m, verse, anotherVerseComponents(ctx, anotherVerseID(translation, verse.RandomVerse.BookID))...)
+}
+
+// lookupVerse fetches the passage for ref, or a random verse when ref is empty or names one of bookGroups.
+// An empty translation falls back to the guild's default.
+func (b *Bot) lookupVerse(ctx context.Context, guildID, translation, ref string) (*BibleVerse, error) {
+	translation = strings.ToLower(translation)
+	if translation == "" {
+		translation = b.translation(guildID)
+	}
+	if !isSupportedTranslation(translation) {
+		return nil, fmt.Errorf("%w: %q", errUnknownTranslation, translation)
+	}
+
+	// Shortcuts such as "nt" or "gospels" draw a random verse from just those books
+	if bookIDs, ok := bookGroups[strings.ToLower(ref)]; ok {
+		return b.verses.RandomFromBooks(ctx, bookIDs, translation)
+	}
+
+	if ref != "" {
+		// Ranges are limited after caching so a reloaded MAX_RANGE_VERSES applies to cached passages too
+		maxVerses := b.config.Load().MaxRangeVerses
+		key := translation + "|" + strings.ToLower(ref)
+		if verse, ok := b.referenceCache.Get(key); ok {
+			return verse.limitVerses(maxVerses), nil
+		}
+		// Identical lookups in flight at the same time, e.g. a double-tapped command, share one upstream call
+		v, err, _ := b.referenceLookups.Do(key, func() (any, error) {
+			verse, err := b.verses.ByReference(ctx, ref, translation)
+			if err != nil {
+				return nil, err
+			}
+			b.referenceCache.Set(key, verse)
+			return verse, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return v.(*BibleVerse).limitVerses(maxVerses), nil
+	}
+
+	if verse, ok := b.randomCache.Get(translation); ok {
+		return verse, nil
+	}
+	verse, err := b.verses.Random(ctx, translation)
+	if err != nil {
+		return nil, err
+	}
+	b.randomCache.Set(translation, verse)
+	return verse, nil
+}
+
+// rateLimitReply tells a user how long to wait before asking for another verse
+func rateLimitReply(ctx context.Context, wait time.Duration) string {
+	return localize(ctx, "rate_limited", int(math.Ceil(wait.Seconds())))
+}
+
+// verseErrorReply turns a verse lookup error into a message for the user, with the request ID for support
+func verseErrorReply(ctx context.Context, err error) string {
+	return errorReply(ctx, verseErrorText(ctx, err))
+}
+
+// verseErrorText explains a verse lookup error in the context's locale
+func verseErrorText(ctx context.Context, err error) string {
+	var bookErr *unknownBookError
+	switch {
+	case errors.As(err, &bookErr):
+		if bookErr.Suggestion != "" {
+			return localize(ctx, "unknown_book_suggest", bookErr.Name, bookErr.Suggestion)
+		}
+		return localize(ctx, "unknown_book", bookErr.Name)
+	case errors.Is(err, errPassageNotFound):
+		return localize(ctx, "passage_not_found")
+	case errors.Is(err, errUnknownTranslation):
+		return localize(ctx, "unknown_translation", strings.Join(SupportedTranslations, ", "))
+	case errors.Is(err, errResponseTooLarge):
+		return localize(ctx, "passage_too_large")
+	case errors.Is(err, errTimeout), errors.Is(err, context.DeadlineExceeded):
+		slog.WarnContext(ctx, "Verse retrieval timed out", "error", err)
+		return localize(ctx, "verse_timeout")
+	case errors.Is(err, errUpstreamUnavailable):
+		slog.WarnContext(ctx, "Bible API unavailable", "error", err)
+		return localize(ctx, "verse_upstream")
+	default:
+		slog.ErrorContext(ctx, "Verse retrieval error", "error", err)
+		return localize(ctx, "verse_unavailable")
+	}
+}
+
+// handleTranslation shows or changes the default translation for the guild
+func (b *Bot) handleTranslation(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "translation_current", strings.ToUpper(b.translation(m.GuildID))))
+		return
+	}
+
+	// The default is per server, so there is nothing to set in a DM
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return
+	}
+
+	// Anyone may look at the default but only admins may change it
+	if !b.isOwner(m.Author.ID) && !isGuildAdmin(s, m) {
+		b.reply(ctx, s, m, localize(ctx, "missing_permission", permissionName(discordgo.PermissionManageServer)))
+		return
+	}
+
+	translation := strings.ToLower(args[0])
+	if !isSupportedTranslation(translation) {
+		b.reply(ctx, s, m, localize(ctx, "translation_invalid", args[0], strings.Join(SupportedTranslations, ", ")))
+		return
+	}
+
+	err := b.settings.Update(m.GuildID, func(gc *GuildConfig) { gc.Translation = translation })
+	if err != nil {
+		slog.ErrorContext(ctx, "Error saving translation", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "translation_set", strings.ToUpper(translation)))
+}
+
+// handleSetPrefix changes the command prefix for the guild
+func (b *Bot) handleSetPrefix(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return
+	}
+	if len(args) != 1 {
+		b.reply(ctx, s, m, localize(ctx, "usage_setprefix", b.prefix(m.GuildID)))
+		return
+	}
+
+	prefix := args[0]
+	if !validPrefix(prefix) {
+		b.reply(ctx, s, m, localize(ctx, "prefix_invalid", MaxPrefixLength))
+		return
+	}
+
+	if err := b.settings.Update(m.GuildID, func(gc *GuildConfig) { gc.Prefix = prefix }); err != nil {
+		slog.ErrorContext(ctx, "Error saving prefix", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "prefix_set", prefix))
+}
+
+// handleDailyChannel makes the current channel the guild's daily verse channel, or turns it off
+func (b *Bot) handleDailyChannel(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return
+	}
+
+	channelID := m.ChannelID
+	if len(args) > 0 && strings.EqualFold(args[0], "off") {
+		channelID = ""
+	}
+
+	if err := b.settings.Update(m.GuildID, func(gc *GuildConfig) { gc.DailyVerseChannelID = channelID }); err != nil {
+		slog.ErrorContext(ctx, "Error saving daily verse channel", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	if channelID == "" {
+		b.reply(ctx, s, m, localize(ctx, "dailychannel_off"))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "dailychannel_set", channelID))
+}
+
+// handleMention turns pinging the requester in verse replies on or off for the guild
+func (b *Bot) handleMention(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return
+	}
+	if len(args) != 1 || !(strings.EqualFold(args[0], "on") || strings.EqualFold(args[0], "off")) {
+		b.reply(ctx, s, m, localize(ctx, "usage_mention", b.prefix(m.GuildID)))
+		return
+	}
+
+	mention := strings.EqualFold(args[0], "on")
+	if err := b.settings.Update(m.GuildID, func(gc *GuildConfig) { gc.MentionRequester = &mention }); err != nil {
+		slog.ErrorContext(ctx, "Error saving mention setting", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	if mention {
+		b.reply(ctx, s, m, localize(ctx, "mention_on"))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "mention_off"))
+}
+
+// handleBookmark saves the last verse shown to the user
+func (b *Bot) handleBookmark(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	verse, ok := b.recent.Last(m.Author.ID)
+	if !ok {
+		b.reply(ctx, s, m, localize(ctx, "bookmark_nothing", b.prefix(m.GuildID)))
+		return
+	}
+
+	added, err := b.bookmarks.Add(m.Author.ID, Bookmark{
+		Reference:   verse.Title(),
+		Translation: verse.Translation.Identifier,
+		SavedAt:     time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Bookmark save error", "user_id", m.Author.ID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "bookmark_failed")))
+		return
+	}
+	if !added {
+		b.reply(ctx, s, m, localize(ctx, "bookmark_exists", verse.Title()))
+		return
+	}
+
+	b.reply(ctx, s, m, localize(ctx, "bookmark_saved", verse.Title()))
+}
+
+// handleBookmarks lists the user's saved verses
+func (b *Bot) handleBookmarks(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	saved, err := b.bookmarks.List(m.Author.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Bookmark list error", "user_id", m.Author.ID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "bookmarks_failed")))
+		return
+	}
+	if len(saved) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "bookmarks_empty", b.prefix(m.GuildID)))
+		return
+	}
+
+	var builder strings.Builder
+	for i, bm := range saved {
+		builder.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, bm.Reference, strings.ToUpper(bm.Translation)))
+	}
+
+	b.replyEmbed(ctx, s, m, &discordgo.MessageEmbed{
+		Title:       localize(ctx, "bookmarks_title"),
+		Description: builder.String(),
+		Color:       b.config.Load().EmbedColor,
+	})
+}
+
+// handleSearch replies with verses matching a keyword
+func (b *Bot) handleSearch(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "usage_search", b.prefix(m.GuildID)))
+		return
+	}
+	if ok, wait := b.verseLimiter.Allow(m.Author.ID); !ok {
+		b.reply(ctx, s, m, rateLimitReply(ctx, wait))
+		return
+	}
+
+	keyword := strings.Join(args, " ")
+	results, err := searchVerses(ctx, keyword)
+	if err != nil {
+		slog.ErrorContext(ctx, "Verse search error", "keyword", keyword, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "search_failed")))
+		return
+	}
+	if len(results) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "search_empty", keyword))
+		return
+	}
+
+	b.replyEmbed(ctx, s, m, b.createSearchEmbed(ctx, keyword, results))
+}
+
+// handleHelp lists every registered command with its description
+func (b *Bot) handleHelp(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	b.replyEmbed(ctx, s, m, b.createHelpEmbed(ctx, m.GuildID))
+}
+
+// createHelpEmbed builds the help embed from the command registry, using the guild's prefix
+func (b *Bot) createHelpEmbed(ctx context.Context, guildID string) *discordgo.MessageEmbed {
+	prefix := b.prefix(guildID)
+
+	var builder strings.Builder
+	for i := range b.commands {
+		c := &b.commands[i]
+		// Owner commands are kept out of the public listing, as are ones turned off here
+		if c.OwnerOnly || b.commandDisabled(guildID, c) {
+			continue
+		}
+		usage := prefix + c.Name
+		if c.Usage != "" {
+			usage += " " + c.Usage
+		}
+		builder.WriteString(fmt.Sprintf("`%s` — %s", usage, c.Description))
+		if len(c.Aliases) > 0 {
+			builder.WriteString(" (also `" + prefix + strings.Join(c.Aliases, "`, `"+prefix) + "`)")
+		}
+		builder.WriteString("\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       localize(ctx, "help_title"),
+		Description: builder.String(),
+		Color:       b.config.Load().EmbedColor,
+	}
+}
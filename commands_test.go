@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeProvider is a VerseProvider that serves a fixed verse or error and records how it was called
+type fakeProvider struct {
+	verse *BibleVerse
+	err   error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (p *fakeProvider) record(call string) (*BibleVerse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls = append(p.calls, call)
+	return p.verse, p.err
+}
+
+func (p *fakeProvider) Random(ctx context.Context, translation string) (*BibleVerse, error) {
+	return p.record("Random " + translation)
+}
+
+func (p *fakeProvider) RandomFromBook(ctx context.Context, book, translation string) (*BibleVerse, error) {
+	return p.record("RandomFromBook " + book + " " + translation)
+}
+
+func (p *fakeProvider) RandomFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error) {
+	return p.record("RandomFromBooks " + strings.Join(bookIDs, ",") + " " + translation)
+}
+
+func (p *fakeProvider) ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	return p.record("ByReference " + ref + " " + translation)
+}
+
+// Calls returns the calls made so far, e.g. "ByReference John 3:16 web"
+func (p *fakeProvider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.calls...)
+}
+
+// sentMessage is a message the bot posted through the fake Discord API
+type sentMessage struct {
+	ChannelID string
+	Content   string                    `json:"content"`
+	Embeds    []*discordgo.MessageEmbed `json:"embeds"`
+}
+
+// fakeDiscord stands in for Discord's REST API, recording every message the bot posts and
+// every interaction it answers
+type fakeDiscord struct {
+	mu        sync.Mutex
+	sent      []sentMessage
+	responses []discordgo.InteractionResponse
+	nextID    atomic.Int64
+}
+
+func (d *fakeDiscord) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Message sends are POST /channels/{id}/messages and interaction responses
+	// POST /interactions/{id}/{token}/callback; everything else just succeeds
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if req.Method == http.MethodPost && len(parts) >= 4 && parts[len(parts)-1] == "callback" && parts[len(parts)-4] == "interactions" {
+		var resp discordgo.InteractionResponse
+		if err := json.NewDecoder(req.Body).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("decoding interaction response: %w", err)
+		}
+
+		d.mu.Lock()
+		d.responses = append(d.responses, resp)
+		d.mu.Unlock()
+
+		return jsonResponse(http.StatusNoContent, struct{}{}), nil
+	}
+	if req.Method == http.MethodPost && len(parts) >= 3 && parts[len(parts)-1] == "messages" && parts[len(parts)-3] == "channels" {
+		var msg sentMessage
+		if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+			return nil, fmt.Errorf("decoding sent message: %w", err)
+		}
+		msg.ChannelID = parts[len(parts)-2]
+
+		d.mu.Lock()
+		d.sent = append(d.sent, msg)
+		d.mu.Unlock()
+
+		return jsonResponse(http.StatusOK, discordgo.Message{ID: fmt.Sprint(d.nextID.Add(1)), ChannelID: msg.ChannelID}), nil
+	}
+	return jsonResponse(http.StatusOK, struct{}{}), nil
+}
+
+// Sent returns the messages posted so far
+func (d *fakeDiscord) Sent() []sentMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]sentMessage(nil), d.sent...)
+}
+
+// Responses returns the interaction responses sent so far
+func (d *fakeDiscord) Responses() []discordgo.InteractionResponse {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]discordgo.InteractionResponse(nil), d.responses...)
+}
+
+// jsonResponse builds an HTTP response with v as its JSON body
+func jsonResponse(status int, v any) *http.Response {
+	body, _ := json.Marshal(v)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// newTestSession creates a session whose REST calls go to a fakeDiscord
+func newTestSession(t *testing.T) (*discordgo.Session, *fakeDiscord) {
+	t.Helper()
+
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	discord := &fakeDiscord{}
+	s.Client = &http.Client{Transport: discord}
+	s.State.User = &discordgo.User{ID: "bot"}
+	return s, discord
+}
+
+// newTestBot creates a bot with the default configuration, verses from verses and its stores under a temporary directory.
+// The verse rate limit is off so tests can ask for as many verses as they like.
+func newTestBot(t *testing.T, verses VerseProvider) *Bot {
+	t.Helper()
+
+	t.Setenv("DISCORD_BOT_TOKEN", "test.bot.token")
+	t.Setenv("VERSE_RATE_LIMIT", "0")
+	config, err := loadConfiguration("")
+	if err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+
+	dir := t.TempDir()
+	bookmarks, err := newJSONBookmarkStore(filepath.Join(dir, "bookmarks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := newJSONSettingsStore(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscriptions, err := newJSONSubscriptionStore(filepath.Join(dir, "subscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return newBot(ctx, config, verses, bookmarks, settings, subscriptions, nil)
+}
+
+// testMessageIDs gives every test message its own ID, since the bot runs each message only once
+var testMessageIDs atomic.Int64
+
+// newTestMessage builds a direct message from userID with the given content
+func newTestMessage(userID, content string) *discordgo.MessageCreate {
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        fmt.Sprintf("msg-%d", testMessageIDs.Add(1)),
+		ChannelID: "channel",
+		Content:   content,
+		Author:    &discordgo.User{ID: userID},
+	}}
+}
+
+// john316 is the verse the fake provider serves
+var john316 = &BibleVerse{
+	Translation: Translation{Identifier: "web", Name: "World English Bible"},
+	RandomVerse: RandomVerse{BookID: "JHN", Book: "John", Chapter: 3, Verse: 16, Text: "For God so loved the world..."},
+}
+
+func TestMessageCreateDispatch(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		content     string
+		wantCalls   []string
+		wantContent string
+		wantTitle   string
+	}{
+		{name: "hello", content: "!hello", wantContent: localize(ctx, "hello", DefaultPrefix)},
+		{name: "random verse", content: "!verse", wantCalls: []string{"Random web"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "alias", content: "!v", wantCalls: []string{"Random web"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "reference", content: "!verse John 3:16", wantCalls: []string{"ByReference John 3:16 web"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "translation and reference", content: "!verse kjv John 3:16", wantCalls: []string{"ByReference John 3:16 kjv"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "book group", content: "!verse gospels", wantCalls: []string{"RandomFromBooks " + strings.Join(bookGroups["gospels"], ",") + " web"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "random from book", content: "!random Psalms", wantCalls: []string{"RandomFromBook Psalms web"}, wantTitle: "📖 John 3:16 (WEB)"},
+		{name: "version alias", content: "!about", wantTitle: localize(ctx, "version_title")},
+		{name: "unknown command", content: "!nonsense", wantContent: localize(ctx, "unknown_command", DefaultPrefix)},
+		{name: "no prefix", content: "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{verse: john316}
+			b := newTestBot(t, provider)
+			s, discord := newTestSession(t)
+
+			b.messageCreate(s, newTestMessage("user", tt.content))
+
+			if calls := provider.Calls(); strings.Join(calls, "\n") != strings.Join(tt.wantCalls, "\n") {
+				t.Errorf("provider calls = %q, want %q", calls, tt.wantCalls)
+			}
+			sent := discord.Sent()
+			if tt.wantContent == "" && tt.wantTitle == "" {
+				if len(sent) != 0 {
+					t.Fatalf("sent %d messages, want none", len(sent))
+				}
+				return
+			}
+			if len(sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(sent))
+			}
+			if sent[0].Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", sent[0].Content, tt.wantContent)
+			}
+			title := ""
+			if len(sent[0].Embeds) > 0 {
+				title = sent[0].Embeds[0].Title
+			}
+			if title != tt.wantTitle {
+				t.Errorf("embed title = %q, want %q", title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestIndexCommandsAliases(t *testing.T) {
+	b := newTestBot(t, &fakeProvider{})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"verse", "verse"},
+		{"v", "verse"},
+		{"p", "ping"},
+		{"about", "version"},
+		{"version", "version"},
+	}
+	for _, tt := range tests {
+		command, ok := b.findCommand(tt.name)
+		if !ok {
+			t.Errorf("findCommand(%q) found nothing, want %s", tt.name, tt.want)
+			continue
+		}
+		if command.Name != tt.want {
+			t.Errorf("findCommand(%q) = %s, want %s", tt.name, command.Name, tt.want)
+		}
+	}
+	if _, ok := b.findCommand("nonsense"); ok {
+		t.Error("findCommand(\"nonsense\") found a command")
+	}
+}
+
+func TestIndexCommandsDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("indexCommands accepted an alias claimed by two commands")
+		}
+	}()
+	indexCommands([]Command{{Name: "verse", Aliases: []string{"v"}}, {Name: "version", Aliases: []string{"v"}}})
+}
+
+func TestVerseErrorReply(t *testing.T) {
+	ctx := withRequestID(context.Background(), "req-1")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not found", errPassageNotFound, localize(ctx, "passage_not_found")},
+		{"wrapped not found", fmt.Errorf("lookup: %w", errPassageNotFound), localize(ctx, "passage_not_found")},
+		{"timeout", fmt.Errorf("%w: retry deadline exceeded", errTimeout), localize(ctx, "verse_timeout")},
+		{"unknown book", &unknownBookError{Name: "Hezekiah"}, localize(ctx, "unknown_book", "Hezekiah")},
+		{"unknown book with suggestion", &unknownBookError{Name: "Jhon", Suggestion: "John"}, localize(ctx, "unknown_book_suggest", "Jhon", "John")},
+		{"unknown translation", fmt.Errorf("%w: %q", errUnknownTranslation, "xyz"), localize(ctx, "unknown_translation", strings.Join(SupportedTranslations, ", "))},
+		{"too large", errResponseTooLarge, localize(ctx, "passage_too_large")},
+		{"upstream", fmt.Errorf("%w: status 503", errUpstreamUnavailable), localize(ctx, "verse_upstream")},
+		{"anything else", errors.New("boom"), localize(ctx, "verse_unavailable")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want + "\n-# " + localize(ctx, "request_ref", "req-1")
+			if got := verseErrorReply(ctx, tt.err); got != want {
+				t.Errorf("verseErrorReply() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMessageCreateErrorReplies(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		content string
+		err     error
+		want    string
+	}{
+		{"not found", "!verse Hezekiah 1:1", errPassageNotFound, localize(ctx, "passage_not_found")},
+		{"timeout", "!verse John 3:16", fmt.Errorf("%w: retry deadline exceeded", errTimeout), localize(ctx, "verse_timeout")},
+		{"unknown book", "!random Jhon", &unknownBookError{Name: "Jhon", Suggestion: "John"}, localize(ctx, "unknown_book_suggest", "Jhon", "John")},
+		{"unavailable", "!verse", errors.New("connection refused"), localize(ctx, "verse_unavailable")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, &fakeProvider{err: tt.err})
+			s, discord := newTestSession(t)
+
+			b.messageCreate(s, newTestMessage("user", tt.content))
+
+			sent := discord.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(sent))
+			}
+			// The reply ends with the request's reference, which is random
+			if !strings.HasPrefix(sent[0].Content, tt.want+"\n-# ") {
+				t.Errorf("reply = %q, want it to start with %q", sent[0].Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateVerseEmbed(t *testing.T) {
+	passage := &BibleVerse{
+		Translation: Translation{Identifier: "kjv", Name: "King James Version"},
+		Reference:   "Genesis 1:1-2",
+		Verses: []RandomVerse{
+			{Book: "Genesis", Chapter: 1, Verse: 1, Text: "In the beginning God created the heaven and the earth."},
+			{Book: "Genesis", Chapter: 1, Verse: 2, Text: "And the earth was without form, and void."},
+		},
+	}
+	tests := []struct {
+		name       string
+		env        map[string]string
+		verse      *BibleVerse
+		wantTitle  string
+		want       string
+		wantAuthor string
+		wantColor  int
+		wantFooter string
+	}{
+		{
+			name:       "single verse",
+			verse:      john316,
+			wantTitle:  "📖 John 3:16 (WEB)",
+			want:       "> For God so loved the world...",
+			wantAuthor: "World English Bible",
+			wantColor:  DefaultEmbedColor,
+		},
+		{
+			name:       "passage",
+			verse:      passage,
+			wantTitle:  "📖 Genesis 1:1-2 (KJV)",
+			want:       "> **1** In the beginning God created the heaven and the earth. **2** And the earth was without form, and void.",
+			wantAuthor: "King James Version",
+			wantColor:  DefaultEmbedColor,
+		},
+		{
+			name:       "branding",
+			env:        map[string]string{"EMBED_COLOR": "#ff8800", "EMBED_EMOJI": "false", "VERSE_FORMAT": "plain", "EMBED_FOOTER": "Verse on Demand"},
+			verse:      john316,
+			wantTitle:  "John 3:16 (WEB)",
+			want:       "For God so loved the world...",
+			wantAuthor: "World English Bible",
+			wantColor:  0xff8800,
+			wantFooter: "Verse on Demand",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			b := newTestBot(t, &fakeProvider{})
+
+			embed := b.createVerseEmbed(tt.verse)
+			if embed.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", embed.Title, tt.wantTitle)
+			}
+			if embed.Description != tt.want {
+				t.Errorf("description = %q, want %q", embed.Description, tt.want)
+			}
+			if embed.Author == nil || embed.Author.Name != tt.wantAuthor {
+				t.Errorf("author = %+v, want %q", embed.Author, tt.wantAuthor)
+			}
+			if embed.Color != tt.wantColor {
+				t.Errorf("color = %#x, want %#x", embed.Color, tt.wantColor)
+			}
+			footer := ""
+			if embed.Footer != nil {
+				footer = embed.Footer.Text
+			}
+			if footer != tt.wantFooter {
+				t.Errorf("footer = %q, want %q", footer, tt.wantFooter)
+			}
+		})
+	}
+}
+
+func TestCreateVerseEmbedTimestamp(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"default", "", true},
+		{"on", "true", true},
+		{"off", "false", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EMBED_TIMESTAMP", tt.value)
+			b := newTestBot(t, &fakeProvider{})
+
+			before := time.Now().Truncate(time.Second)
+			embed := b.createVerseEmbed(john316)
+			after := time.Now()
+
+			body, err := json.Marshal(embed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(body, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := fields["timestamp"]; ok != tt.want {
+				t.Fatalf("embed JSON %s has a timestamp: %v, want %v", body, ok, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+
+			sent, err := time.Parse(time.RFC3339, embed.Timestamp)
+			if err != nil {
+				t.Fatalf("timestamp %q is not RFC 3339: %v", embed.Timestamp, err)
+			}
+			if sent.Before(before) || sent.After(after) {
+				t.Errorf("timestamp %s is not between %s and %s", sent, before, after)
+			}
+		})
+	}
+}
+
+func TestEmbedTimestampUTC(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	if got, want := embedTimestamp(time.Date(2024, 3, 10, 8, 0, 0, 0, newYork)), "2024-03-10T12:00:00Z"; got != want {
+		t.Errorf("embedTimestamp() = %q, want %q", got, want)
+	}
+}
+
+func TestMessageCreateRecoversFromPanic(t *testing.T) {
+	b := newTestBot(t, &fakeProvider{verse: john316})
+	b.byName["boom"] = &Command{Name: "boom", Handler: func(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+		panic("handler bug")
+	}}
+	s, discord := newTestSession(t)
+
+	// A panic escaping messageCreate would fail the whole test binary here
+	b.messageCreate(s, newTestMessage("user", "!boom"))
+
+	ctx := context.Background()
+	sent := discord.Sent()
+	if len(sent) != 1 || sent[0].Content != localize(ctx, "internal_error") {
+		t.Fatalf("sent %+v, want the internal error notice", sent)
+	}
+	if active := b.active.Load(); active != 0 {
+		t.Errorf("%d handlers still marked active after the panic", active)
+	}
+	if !b.drain(time.Second) {
+		t.Error("drain() timed out waiting for the panicked handler")
+	}
+
+	// The bot carries on answering commands
+	b.messageCreate(s, newTestMessage("user", "!hello"))
+	sent = discord.Sent()
+	if len(sent) != 2 || sent[1].Content != localize(ctx, "hello", DefaultPrefix) {
+		t.Errorf("sent %+v after the panic, want a greeting", sent)
+	}
+}
+
+// blockingProvider serves john316 by reference, holding every call until release is closed
+type blockingProvider struct {
+	fakeProvider
+	release chan struct{}
+	started chan struct{}
+}
+
+func (p *blockingProvider) ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return p.fakeProvider.ByReference(ctx, ref, translation)
+}
+
+// waitForSharedLookups waits until n goroutines are inside the bot's singleflight group: the one
+// running the lookup and the rest waiting on its result
+func waitForSharedLookups(t *testing.T, n int) {
+	t.Helper()
+
+	buf := make([]byte, 1<<20)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stacks := string(buf[:runtime.Stack(buf, true)])
+		if strings.Count(stacks, "singleflight.(*Group).Do(") >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d lookups never joined the shared call", n)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestLookupVerseSharesConcurrentLookups(t *testing.T) {
+	// Without the cache, only the shared in-flight call can stop the second lookup reaching upstream
+	t.Setenv("REFERENCE_CACHE_TTL", "0")
+	provider := &blockingProvider{
+		fakeProvider: fakeProvider{verse: john316},
+		release:      make(chan struct{}),
+		started:      make(chan struct{}, 2),
+	}
+	b := newTestBot(t, provider)
+
+	var wg sync.WaitGroup
+	verses := make([]*BibleVerse, 2)
+	errs := make([]error, 2)
+	lookup := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verses[i], errs[i] = b.lookupVerse(context.Background(), "", "", "John 3:16")
+		}()
+	}
+
+	// The first lookup is held upstream until the second has joined it
+	lookup(0)
+	<-provider.started
+	lookup(1)
+	waitForSharedLookups(t, 2)
+	close(provider.release)
+	wg.Wait()
+
+	for i := range verses {
+		if errs[i] != nil || verses[i] != john316 {
+			t.Errorf("lookup %d = %v, %v, want John 3:16", i, verses[i], errs[i])
+		}
+	}
+	if calls := provider.Calls(); len(calls) != 1 {
+		t.Errorf("provider calls = %q, want a single call", calls)
+	}
+
+	// Once the first lookup is done, a new one goes upstream again
+	if _, err := b.lookupVerse(context.Background(), "", "", "John 3:16"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := provider.Calls(); len(calls) != 2 {
+		t.Errorf("provider calls = %q, want a second call after the first finished", calls)
+	}
+}
+
+func TestDailyVerseRetryAfterFailedFetch(t *testing.T) {
+	verses := &fakeProvider{err: fmt.Errorf("%w: status 503", errUpstreamUnavailable)}
+	b := newTestBot(t, verses)
+	s, discord := newTestSession(t)
+	ctx := context.Background()
+
+	b.messageCreate(s, newTestMessage("user", "!dailyverse"))
+	if sent := discord.Sent(); len(sent) != 1 || !strings.HasPrefix(sent[0].Content, verseErrorReply(ctx, verses.err)+"\n-# ") {
+		t.Fatalf("sent %+v, want the fetch error", sent)
+	}
+
+	// The failed post did not count against the debounce, so a retry goes through
+	verses.mu.Lock()
+	verses.verse, verses.err = john316, nil
+	verses.mu.Unlock()
+	b.messageCreate(s, newTestMessage("user", "!dailyverse"))
+	sent := discord.Sent()
+	if len(sent) != 2 || len(sent[1].Embeds) != 1 {
+		t.Fatalf("sent %+v after the retry, want the verse", sent)
+	}
+
+	// The successful post does count
+	b.messageCreate(s, newTestMessage("user", "!dailyverse"))
+	if sent := discord.Sent(); len(sent) != 2 {
+		t.Errorf("sent %+v, want the third !dailyverse debounced", sent)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"golang.org/x/sync/errgroup"
+)
+
+// comparison is one translation's side of !compare: the verse, or why it could not be fetched
+type comparison struct {
+	Translation string
+	Verse       *BibleVerse
+	Err         error
+}
+
+// handleCompare shows a passage in several translations side by side, e.g. !compare John 3:16 kjv web
+func (b *Bot) handleCompare(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	// Translations are taken from the end so references with spaces like "1 John 4:8" still work
+	var translations []string
+	for len(args) > 0 && isSupportedTranslation(strings.ToLower(args[len(args)-1])) {
+		translation := strings.ToLower(args[len(args)-1])
+		if !slices.Contains(translations, translation) {
+			translations = append(translations, translation)
+		}
+		args = args[:len(args)-1]
+	}
+	slices.Reverse(translations)
+
+	if len(args) == 0 || len(translations) < 2 {
+		prefix := b.prefix(m.GuildID)
+		b.reply(ctx, s, m, localize(ctx, "usage_compare", prefix, prefix))
+		return
+	}
+	if len(translations) > MaxCompareTranslations {
+		b.reply(ctx, s, m, localize(ctx, "compare_too_many", MaxCompareTranslations))
+		return
+	}
+	if ok, wait := b.verseLimiter.Allow(m.Author.ID); !ok {
+		b.reply(ctx, s, m, rateLimitReply(ctx, wait))
+		return
+	}
+
+	ref := strings.Join(args, " ")
+	results := b.compareVerses(ctx, m.GuildID, ref, translations)
+
+	// Only give up when there is nothing at all to show
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed == len(results) {
+		b.reply(ctx, s, m, verseErrorReply(ctx, results[0].Err))
+		return
+	}
+
+	b.replyEmbed(ctx, s, m, b.createCompareEmbed(ctx, ref, results))
+}
+
+// compareVerses fetches ref in each translation, at most CompareConcurrency at a time.
+// Results keep the order of translations and a failure is recorded against its translation only.
+func (b *Bot) compareVerses(ctx context.Context, guildID, ref string, translations []string) []comparison {
+	results := make([]comparison, len(translations))
+
+	var g errgroup.Group
+	g.SetLimit(CompareConcurrency)
+	for i, translation := range translations {
+		g.Go(func() error {
+			verse, err := b.lookupVerse(ctx, guildID, translation, ref)
+			results[i] = comparison{Translation: translation, Verse: verse, Err: err}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return results
+}
+
+// createCompareEmbed puts each translation of a passage in its own field
+func (b *Bot) createCompareEmbed(ctx context.Context, ref string, results []comparison) *discordgo.MessageEmbed {
+	config := b.config.Load()
+	embed := &discordgo.MessageEmbed{
+		Title: ref,
+		Color: config.EmbedColor,
+	}
+
+	for _, r := range results {
+		name := strings.ToUpper(r.Translation)
+		if r.Err != nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  name,
+				Value: "⚠️ " + verseErrorReply(ctx, r.Err),
+			})
+			continue
+		}
+
+		// Any successful lookup knows the passage's proper title
+		embed.Title = r.Verse.Title()
+		if r.Verse.Translation.Name != "" {
+			name = fmt.Sprintf("%s — %s", name, r.Verse.Translation.Name)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  name,
+			Value: formatVerseText(r.Verse.Text(), config.VerseFormat),
+		})
+	}
+
+	return limitEmbed(embed)
+}
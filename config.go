@@ -0,0 +1,696 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/joho/godotenv"
+)
+
+// utf8BOM is the byte order mark some Windows editors put at the start of text files
+const utf8BOM = "\ufeff"
+
+// discordTokenPattern is the shape of a bot token: three base64url parts separated by dots
+var discordTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+
+// DefaultIntents are the gateway intents required to read and answer prefix commands in servers and DMs,
+// plus server reactions for the 📖 verse reaction
+const DefaultIntents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessages | discordgo.IntentMessageContent
+
+// intentNames maps the names accepted in DISCORD_INTENTS to gateway intents
+var intentNames = map[string]discordgo.Intent{
+	"guilds":                   discordgo.IntentsGuilds,
+	"guild_members":            discordgo.IntentsGuildMembers,
+	"guild_presences":          discordgo.IntentsGuildPresences,
+	"guild_messages":           discordgo.IntentsGuildMessages,
+	"guild_message_reactions":  discordgo.IntentsGuildMessageReactions,
+	"direct_messages":          discordgo.IntentsDirectMessages,
+	"direct_message_reactions": discordgo.IntentsDirectMessageReactions,
+	"message_content":          discordgo.IntentMessageContent,
+}
+
+// AppConfig holds application-wide configuration
+type AppConfig struct {
+	DiscordToken string
+	Debug        bool
+
+	// LogMessageContent logs every message the bot sees, content included. It defaults to Debug.
+	LogMessageContent bool
+
+	Prefix     string
+	Intents    discordgo.Intent
+	DevGuildID string
+
+	// EnvFile is the -env flag value the configuration was loaded with, kept for !reload
+	EnvFile string
+
+	// OwnerID is the Discord user allowed to run admin commands; empty disables them
+	OwnerID string
+
+	// CommandTimeout bounds a whole command, retries and failover included. Each HTTP
+	// attempt is separately limited to the shorter RequestTimeout.
+	CommandTimeout time.Duration
+
+	// Cache lifetimes for random verses (0 disables) and reference lookups
+	VerseCacheTTL     time.Duration
+	ReferenceCacheTTL time.Duration
+
+	// Per-user verse rate limit: VerseRateLimit requests per VerseRateWindow (0 disables)
+	VerseRateLimit  int
+	VerseRateWindow time.Duration
+
+	// Daily verse schedule: post to DailyVerseChannelID at DailyVerseTime (HH:MM) in DailyVerseLocation
+	DailyVerseChannelID string
+	DailyVerseTime      string
+	DailyVerseLocation  *time.Location
+	DailyVerseDebounce  time.Duration
+
+	// DailyVerseWebhookURL posts the global daily verse through a webhook instead, winning over DailyVerseChannelID
+	DailyVerseWebhookURL string
+
+	// Auto verse: post a random verse to AutoVerseChannelID every AutoVerseInterval (0 disables)
+	AutoVerseChannelID string
+	AutoVerseInterval  time.Duration
+
+	// HealthPort serves /healthz and /readyz when non-zero
+	HealthPort int
+
+	// MaxResponseBytes caps how much of an API response is read
+	MaxResponseBytes int
+
+	// MaxRangeVerses caps how many verses of a looked-up range are shown
+	MaxRangeVerses int
+
+	// MaxConcurrentRequests caps Bible API requests in flight; more wait their turn
+	MaxConcurrentRequests int
+
+	// Presence: the bot shows BotStatuses in turn, one every BotStatusInterval, as BotActivityType
+	BotStatuses       []string
+	BotActivityType   discordgo.ActivityType
+	BotStatusInterval time.Duration
+
+	// UserAgent identifies the bot to the Bible APIs
+	UserAgent string
+
+	// BookmarksFile is where user bookmarks are persisted; SettingsFile holds per-guild settings and
+	// each user's reading streak, and SubscriptionsFile the daily verse DM subscribers
+	BookmarksFile     string
+	SettingsFile      string
+	SubscriptionsFile string
+
+	// SubscriptionMaxFailures unsubscribes a user after this many refused daily DMs in a row (0 never does)
+	SubscriptionMaxFailures int
+
+	// Embed branding; an empty EmbedThumbnailURL shows no thumbnail and EmbedTimestamp shows when a verse was sent
+	EmbedColor        int
+	EmbedFooter       string
+	EmbedEmoji        bool
+	EmbedThumbnailURL string
+	EmbedTimestamp    bool
+
+	// VerseImage renders verses as image cards instead of text
+	VerseImage bool
+
+	// VerseFormat is how verse text is rendered in embeds: blockquote, plain or code
+	VerseFormat string
+
+	// ReplyReferences threads command answers under the triggering message as Discord replies
+	ReplyReferences bool
+
+	// Text-to-speech service for !verse --audio; an empty TTSEndpoint turns audio off
+	TTSEndpoint string
+	TTSAPIKey   string
+
+	// Optional audit log of commands, rotated at AuditLogMaxSizeMB keeping AuditLogMaxBackups old files
+	AuditLogFile       string
+	AuditLogMaxSizeMB  int
+	AuditLogMaxBackups int
+
+	// HandleEdits runs commands that users add to a message by editing it. It is off by
+	// default since it lets one message be reused to trigger commands.
+	HandleEdits bool
+
+	// StartupSelfTest fetches one verse once connected; with StartupSelfTestRequired a failure stops the bot
+	StartupSelfTest         bool
+	StartupSelfTestRequired bool
+
+	// MentionRequester pings the user who asked for a verse; servers can override it with !mention
+	MentionRequester bool
+
+	// DefaultLocale is used when Discord does not provide a supported locale
+	DefaultLocale string
+
+	// DefaultTranslation is used in servers that have not picked one; empty means the built-in default
+	DefaultTranslation string
+
+	// Sharding: this process runs shard ShardID of ShardCount. A zero ShardCount
+	// means a single unsharded connection; ShardAuto asks Discord for the count.
+	ShardID    int
+	ShardCount int
+	ShardAuto  bool
+
+	// Channel restrictions for commands. An empty allowlist allows every channel;
+	// the denylist always wins.
+	AllowedChannels map[string]bool
+	DeniedChannels  map[string]bool
+
+	// DisabledCommands are command names or aliases turned off in every server
+	DisabledCommands map[string]bool
+
+	// FeedbackChannelID receives !feedback messages; empty turns the command off
+	FeedbackChannelID string
+}
+
+// loadConfiguration handles loading and validating application configuration.
+// envFile overrides the env file location; when empty ENV_FILE and then EnvFileName are used.
+func loadConfiguration(envFile string) (*AppConfig, error) {
+	// Load environment variables from the env file, if there is one
+	if err := loadEnvFile(envFile, false); err != nil {
+		return nil, err
+	}
+	return configFromEnvironment(envFile)
+}
+
+// reloadConfiguration re-reads the env file, letting its values replace ones loaded earlier,
+// and builds a fresh configuration from the result.
+func reloadConfiguration(envFile string) (*AppConfig, error) {
+	if err := loadEnvFile(envFile, true); err != nil {
+		return nil, err
+	}
+	return configFromEnvironment(envFile)
+}
+
+// configFromEnvironment builds and validates the configuration from the process environment.
+// Every problem found is reported together so an operator can fix them in one pass.
+func configFromEnvironment(envFile string) (*AppConfig, error) {
+	var err error
+	var problems configProblems
+
+	// Retrieve and validate required configuration values
+	config := &AppConfig{
+		DiscordToken: cleanEnvValue(os.Getenv("DISCORD_BOT_TOKEN")),
+		Debug:        os.Getenv("DEBUG") == "true",
+		Prefix:       os.Getenv("COMMAND_PREFIX"),
+		EnvFile:      envFile,
+	}
+	// A token file, e.g. a Docker or Vault secret, takes precedence over the inline token
+	if path := os.Getenv("DISCORD_BOT_TOKEN_FILE"); path != "" {
+		config.DiscordToken, err = readSecretFile(path)
+		if err != nil {
+			problems.add(fmt.Errorf("DISCORD_BOT_TOKEN_FILE: %w", err))
+		}
+	}
+	config.DevGuildID, err = snowflakeFromEnv("DEV_GUILD_ID")
+	problems.add(err)
+	config.OwnerID, err = snowflakeFromEnv("OWNER_ID")
+	problems.add(err)
+
+	// Message content is personal data, so it is only logged in debug mode unless asked for explicitly
+	config.LogMessageContent, err = boolFromEnv("LOG_MESSAGE_CONTENT", config.Debug)
+	problems.add(err)
+
+	// Fall back to the default prefix when none is configured
+	if config.Prefix == "" {
+		config.Prefix = DefaultPrefix
+	}
+
+	// Intents default to what prefix commands need but can be trimmed, e.g. for presence-only deployments
+	config.Intents = DefaultIntents
+	if raw := os.Getenv("DISCORD_INTENTS"); raw != "" {
+		config.Intents, err = parseIntents(raw)
+		problems.add(err)
+	}
+
+	config.CommandTimeout, err = durationFromEnv("COMMAND_TIMEOUT", DefaultCommandTimeout)
+	problems.add(err)
+	if err == nil && config.CommandTimeout == 0 {
+		problems.add(errors.New("COMMAND_TIMEOUT must be greater than zero"))
+	}
+
+	// Random verse caching is opt-in since it trades away randomness
+	config.VerseCacheTTL, err = durationFromEnv("VERSE_CACHE_TTL", 0)
+	problems.add(err)
+	config.ReferenceCacheTTL, err = durationFromEnv("REFERENCE_CACHE_TTL", DefaultReferenceCacheTTL)
+	problems.add(err)
+
+	// Per-user rate limiting protects the upstream API from spam
+	config.VerseRateLimit, err = intFromEnv("VERSE_RATE_LIMIT", DefaultVerseRateLimit)
+	problems.add(err)
+	config.VerseRateWindow, err = durationFromEnv("VERSE_RATE_WINDOW", DefaultVerseRateWindow)
+	problems.add(err)
+
+	// Daily verse schedule
+	config.DailyVerseChannelID, err = snowflakeFromEnv("DAILY_VERSE_CHANNEL_ID")
+	problems.add(err)
+	config.DailyVerseTime = os.Getenv("DAILY_VERSE_TIME")
+	if config.DailyVerseTime == "" {
+		config.DailyVerseTime = DefaultDailyVerseTime
+	}
+	if _, _, err := parseClock(config.DailyVerseTime); err != nil {
+		problems.add(fmt.Errorf("DAILY_VERSE_TIME: %w", err))
+	}
+	config.DailyVerseLocation = time.UTC
+	if tz := os.Getenv("DAILY_VERSE_TIMEZONE"); tz != "" {
+		if config.DailyVerseLocation, err = time.LoadLocation(tz); err != nil {
+			problems.add(fmt.Errorf("DAILY_VERSE_TIMEZONE: %w", err))
+		}
+	}
+
+	config.DailyVerseWebhookURL = os.Getenv("DAILY_VERSE_WEBHOOK_URL")
+	if config.DailyVerseWebhookURL != "" {
+		if _, _, err := parseWebhookURL(config.DailyVerseWebhookURL); err != nil {
+			problems.add(fmt.Errorf("DAILY_VERSE_WEBHOOK_URL: %w", err))
+		} else if config.DailyVerseChannelID != "" {
+			slog.Warn("Both DAILY_VERSE_CHANNEL_ID and DAILY_VERSE_WEBHOOK_URL are set, posting through the webhook only")
+		}
+	}
+
+	config.FeedbackChannelID, err = snowflakeFromEnv("FEEDBACK_CHANNEL_ID")
+	problems.add(err)
+
+	config.DailyVerseDebounce, err = durationFromEnv("DAILY_VERSE_DEBOUNCE", DefaultDailyVerseDebounce)
+	problems.add(err)
+
+	// The auto verse needs both a channel and an interval, and a floor keeps it from flooding the channel
+	config.AutoVerseChannelID, err = snowflakeFromEnv("AUTO_VERSE_CHANNEL_ID")
+	problems.add(err)
+	config.AutoVerseInterval, err = durationFromEnv("AUTO_VERSE_INTERVAL", 0)
+	problems.add(err)
+	switch {
+	case err != nil:
+	case config.AutoVerseInterval != 0 && config.AutoVerseInterval < MinAutoVerseInterval:
+		problems.add(fmt.Errorf("AUTO_VERSE_INTERVAL must be at least %s", MinAutoVerseInterval))
+	case config.AutoVerseInterval != 0 && config.AutoVerseChannelID == "":
+		problems.add(errors.New("AUTO_VERSE_INTERVAL is set but AUTO_VERSE_CHANNEL_ID is not"))
+	case config.AutoVerseInterval == 0 && config.AutoVerseChannelID != "":
+		problems.add(errors.New("AUTO_VERSE_CHANNEL_ID is set but AUTO_VERSE_INTERVAL is not"))
+	}
+
+	// Health probes are disabled unless a port is given
+	config.HealthPort, err = portFromEnv("HEALTH_PORT")
+	problems.add(err)
+
+	// Whole chapters need far more room than single verses
+	config.MaxResponseBytes, err = intFromEnv("MAX_RESPONSE_BYTES", DefaultMaxResponseBytes)
+	problems.add(err)
+	if err == nil && config.MaxResponseBytes == 0 {
+		problems.add(errors.New("MAX_RESPONSE_BYTES must be greater than zero"))
+	}
+
+	// Long ranges such as Genesis 1:1-50:26 are cut short instead of flooding the channel
+	config.MaxRangeVerses, err = intFromEnv("MAX_RANGE_VERSES", DefaultMaxRangeVerses)
+	problems.add(err)
+	if err == nil && config.MaxRangeVerses == 0 {
+		problems.add(errors.New("MAX_RANGE_VERSES must be greater than zero"))
+	}
+
+	// The daily broadcast and button spam can otherwise fire off requests without bound
+	config.MaxConcurrentRequests, err = intFromEnv("MAX_CONCURRENT_REQUESTS", DefaultMaxAPIRequests)
+	problems.add(err)
+	if err == nil && config.MaxConcurrentRequests == 0 {
+		problems.add(errors.New("MAX_CONCURRENT_REQUESTS must be greater than zero"))
+	}
+
+	// The presence defaults to "Watching for !verse"
+	config.BotStatuses = statusesFromEnv(config.Prefix)
+	config.BotActivityType = discordgo.ActivityTypeWatching
+	if raw := os.Getenv("BOT_ACTIVITY_TYPE"); raw != "" {
+		config.BotActivityType, err = parseActivityType(raw)
+		problems.add(err)
+	}
+	config.BotStatusInterval, err = durationFromEnv("BOT_STATUS_INTERVAL", DefaultBotStatusInterval)
+	problems.add(err)
+	if err == nil && config.BotStatusInterval == 0 {
+		problems.add(errors.New("BOT_STATUS_INTERVAL must be greater than zero"))
+	}
+
+	// Upstream APIs get a descriptive User-Agent rather than Go's default
+	config.UserAgent = os.Getenv("HTTP_USER_AGENT")
+	if config.UserAgent == "" {
+		config.UserAgent = fmt.Sprintf("verseondemanddiscord/%s (+%s)", version, ProjectURL)
+	}
+
+	// Bookmarks are stored next to the bot unless told otherwise
+	config.BookmarksFile = os.Getenv("BOOKMARKS_FILE")
+	if config.BookmarksFile == "" {
+		config.BookmarksFile = DefaultBookmarksFile
+	}
+	config.SettingsFile = os.Getenv("SETTINGS_FILE")
+	if config.SettingsFile == "" {
+		config.SettingsFile = DefaultSettingsFile
+	}
+	config.SubscriptionsFile = os.Getenv("SUBSCRIPTIONS_FILE")
+	if config.SubscriptionsFile == "" {
+		config.SubscriptionsFile = DefaultSubscriptionsFile
+	}
+	config.SubscriptionMaxFailures, err = intFromEnv("SUBSCRIPTION_MAX_FAILURES", DefaultMaxDMFailures)
+	problems.add(err)
+
+	// Embed branding. A bad color, like a bad thumbnail, only costs some decoration.
+	config.EmbedColor = DefaultEmbedColor
+	if raw := os.Getenv("EMBED_COLOR"); raw != "" {
+		if color, err := parseHexColor(raw); err == nil {
+			config.EmbedColor = color
+		} else {
+			slog.Warn("Ignoring EMBED_COLOR, using the default color", "value", raw, "error", err)
+		}
+	}
+	config.EmbedFooter = os.Getenv("EMBED_FOOTER")
+
+	// A bad thumbnail only costs some decoration, so it is dropped rather than refused
+	if raw := os.Getenv("EMBED_THUMBNAIL_URL"); raw != "" {
+		if isHTTPURL(raw) {
+			config.EmbedThumbnailURL = raw
+		} else {
+			slog.Warn("Ignoring EMBED_THUMBNAIL_URL, it must be an http or https URL", "value", raw)
+		}
+	}
+	config.EmbedEmoji, err = boolFromEnv("EMBED_EMOJI", true)
+	problems.add(err)
+	config.EmbedTimestamp, err = boolFromEnv("EMBED_TIMESTAMP", true)
+	problems.add(err)
+	config.VerseImage, err = boolFromEnv("VERSE_IMAGE", false)
+	problems.add(err)
+	config.VerseFormat = strings.ToLower(os.Getenv("VERSE_FORMAT"))
+	if config.VerseFormat == "" {
+		config.VerseFormat = FormatBlockquote
+	}
+	if !isVerseFormat(config.VerseFormat) {
+		problems.add(fmt.Errorf("VERSE_FORMAT must be one of %s, got %q", strings.Join(VerseFormats, ", "), config.VerseFormat))
+	}
+
+	config.ReplyReferences, err = boolFromEnv("REPLY_REFERENCES", true)
+	problems.add(err)
+	config.MentionRequester, err = boolFromEnv("MENTION_REQUESTER", false)
+	problems.add(err)
+	config.HandleEdits, err = boolFromEnv("HANDLE_EDITS", false)
+	problems.add(err)
+
+	// Requiring the self-test implies running it
+	config.StartupSelfTestRequired, err = boolFromEnv("STARTUP_SELFTEST_REQUIRED", false)
+	problems.add(err)
+	config.StartupSelfTest, err = boolFromEnv("STARTUP_SELFTEST", config.StartupSelfTestRequired)
+	problems.add(err)
+
+	config.AuditLogFile = os.Getenv("AUDIT_LOG_FILE")
+	config.AuditLogMaxSizeMB, err = intFromEnv("AUDIT_LOG_MAX_SIZE_MB", DefaultAuditLogMaxSizeMB)
+	problems.add(err)
+	config.AuditLogMaxBackups, err = intFromEnv("AUDIT_LOG_MAX_BACKUPS", DefaultAuditLogMaxBackups)
+	problems.add(err)
+
+	// Audio needs an external TTS service and its credentials
+	config.TTSEndpoint = os.Getenv("TTS_ENDPOINT")
+	config.TTSAPIKey = os.Getenv("TTS_API_KEY")
+	if config.TTSEndpoint != "" && !isHTTPURL(config.TTSEndpoint) {
+		problems.add(fmt.Errorf("TTS_ENDPOINT must be an http or https URL, got %q", config.TTSEndpoint))
+	}
+
+	config.DefaultLocale = os.Getenv("DEFAULT_LOCALE")
+
+	config.DefaultTranslation = strings.ToLower(os.Getenv("DEFAULT_TRANSLATION"))
+	if config.DefaultTranslation != "" && !isSupportedTranslation(config.DefaultTranslation) {
+		problems.add(fmt.Errorf("DEFAULT_TRANSLATION must be one of %s, got %q", strings.Join(SupportedTranslations, ", "), config.DefaultTranslation))
+	}
+
+	// Sharding is off unless SHARD_COUNT is set
+	if raw := os.Getenv("SHARD_COUNT"); strings.EqualFold(raw, "auto") {
+		config.ShardAuto = true
+	} else {
+		config.ShardCount, err = intFromEnv("SHARD_COUNT", 0)
+		problems.add(err)
+	}
+	config.ShardID, err = intFromEnv("SHARD_ID", 0)
+	problems.add(err)
+	if config.ShardCount > 0 && config.ShardID >= config.ShardCount {
+		problems.add(fmt.Errorf("SHARD_ID must be less than SHARD_COUNT (%d), got %d", config.ShardCount, config.ShardID))
+	}
+	if config.ShardID > 0 && config.ShardCount == 0 && !config.ShardAuto {
+		problems.add(errors.New("SHARD_ID requires SHARD_COUNT to be set"))
+	}
+
+	// Channel restrictions
+	config.AllowedChannels, err = snowflakeSetFromEnv("ALLOWED_CHANNELS")
+	problems.add(err)
+	config.DeniedChannels, err = snowflakeSetFromEnv("DENIED_CHANNELS")
+	problems.add(err)
+
+	// Commands the operator has turned off everywhere; names are matched like typed commands, in lower case
+	config.DisabledCommands = make(map[string]bool)
+	for name := range idSetFromEnv("DISABLED_COMMANDS") {
+		config.DisabledCommands[strings.ToLower(name)] = true
+	}
+
+	// Validate critical configuration; a bad token file has already been reported
+	if config.DiscordToken == "" && os.Getenv("DISCORD_BOT_TOKEN_FILE") == "" {
+		problems.add(errors.New("DISCORD_BOT_TOKEN or DISCORD_BOT_TOKEN_FILE is required in the environment or env file"))
+	} else if config.DiscordToken != "" && !discordTokenPattern.MatchString(config.DiscordToken) {
+		// Caught here, a stray character gives a clearer error than Discord rejecting the login
+		problems.add(errors.New(`Discord bot token looks malformed: expected three dot-separated parts without the "Bot " prefix, spaces or quotes`))
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return config, nil
+}
+
+// configProblems collects every configuration error found so they can be reported at once
+type configProblems []error
+
+// add records err, ignoring nil so callers can pass parse results straight through
+func (p *configProblems) add(err error) {
+	if err != nil {
+		*p = append(*p, err)
+	}
+}
+
+func (p configProblems) Error() string {
+	messages := make([]string, len(p))
+	for i, err := range p {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d configuration problem(s): %s", len(p), strings.Join(messages, "; "))
+}
+
+func (p configProblems) Unwrap() []error {
+	return p
+}
+
+// loadEnvFile loads variables from an env file, overriding ones already set only when override is true.
+// A missing default file is fine since containers usually inject the environment directly,
+// but an explicitly requested file must exist.
+func loadEnvFile(path string, override bool) error {
+	explicit := true
+	if path == "" {
+		path = os.Getenv("ENV_FILE")
+	}
+	if path == "" {
+		path = EnvFileName
+		explicit = false
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		slog.Info("No env file found, using process environment", "path", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error loading %s file: %w", path, err)
+	}
+
+	// A byte order mark would otherwise become part of the first variable's name
+	values, err := godotenv.UnmarshalBytes(bytes.TrimPrefix(data, []byte(utf8BOM)))
+	if err != nil {
+		return fmt.Errorf("error loading %s file: %w", path, err)
+	}
+
+	// Like godotenv.Load, values already in the environment win unless overriding
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set && !override {
+			continue
+		}
+		if err := os.Setenv(key, cleanEnvValue(value)); err != nil {
+			return fmt.Errorf("error loading %s file: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// cleanEnvValue drops a byte order mark and the whitespace and carriage returns that
+// env files saved on Windows can leave around a value
+func cleanEnvValue(value string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), utf8BOM))
+}
+
+// readSecretFile reads a secret such as a token from path, trimming surrounding whitespace and any byte order mark
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	secret := cleanEnvValue(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return secret, nil
+}
+
+// durationFromEnv parses a duration such as "30s" from the named variable, returning fallback when unset
+func durationFromEnv(name string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration like 30s, got %q", name, raw)
+	}
+	return d, nil
+}
+
+// intFromEnv parses a non-negative integer from the named variable, returning fallback when unset
+func intFromEnv(name string, fallback int) (int, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, raw)
+	}
+	return n, nil
+}
+
+// parseClock parses a 24-hour "HH:MM" time of day
+func parseClock(raw string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected a time like 08:00, got %q", raw)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// boolFromEnv parses a boolean such as "true" or "0" from the named variable, returning fallback when unset
+func boolFromEnv(name string, fallback bool) (bool, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", name, raw)
+	}
+	return b, nil
+}
+
+// idSetFromEnv reads a comma-separated list of IDs from the named variable into a set
+func idSetFromEnv(name string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv(name), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// snowflakeFromEnv reads a Discord ID from the named variable, which may be unset
+func snowflakeFromEnv(name string) (string, error) {
+	id := strings.TrimSpace(os.Getenv(name))
+	if id != "" && !isSnowflake(id) {
+		return "", fmt.Errorf("%s must be a Discord ID, got %q", name, id)
+	}
+	return id, nil
+}
+
+// snowflakeSetFromEnv is idSetFromEnv for lists that must contain only Discord IDs
+func snowflakeSetFromEnv(name string) (map[string]bool, error) {
+	ids := idSetFromEnv(name)
+	var invalid []string
+	for id := range ids {
+		if !isSnowflake(id) {
+			invalid = append(invalid, strconv.Quote(id))
+		}
+	}
+	if len(invalid) > 0 {
+		slices.Sort(invalid)
+		return nil, fmt.Errorf("%s must only contain Discord IDs, got %s", name, strings.Join(invalid, ", "))
+	}
+	return ids, nil
+}
+
+// isSnowflake reports whether id looks like a Discord snowflake: 17 to 20 digits
+func isSnowflake(id string) bool {
+	if len(id) < 17 || len(id) > 20 {
+		return false
+	}
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// portFromEnv reads a TCP port from the named variable, returning 0 when unset
+func portFromEnv(name string) (int, error) {
+	port, err := intFromEnv(name, 0)
+	if err != nil {
+		return 0, err
+	}
+	if port > 65535 {
+		return 0, fmt.Errorf("%s must be a port between 1 and 65535, got %d", name, port)
+	}
+	return port, nil
+}
+
+// parseHexColor parses an RGB color such as "#3498db", "0x3498db" or "3498db"
+func parseHexColor(raw string) (int, error) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(raw)), "#"), "0x")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("expected 6 hex digits, got %q", raw)
+	}
+
+	color, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex color %q", raw)
+	}
+	return int(color), nil
+}
+
+// parseIntents reads a comma-separated list of intent names, or a raw numeric bitmask
+func parseIntents(raw string) (discordgo.Intent, error) {
+	if n, err := strconv.Atoi(raw); err == nil {
+		return discordgo.Intent(n), nil
+	}
+
+	var intents discordgo.Intent
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		intent, ok := intentNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown intent %q in DISCORD_INTENTS", name)
+		}
+		intents |= intent
+	}
+	return intents, nil
+}
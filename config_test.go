@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestEmbedColorFromEnvironment(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{"", DefaultEmbedColor},
+		{"#ff0000", 0xff0000},
+		{"0x00FF00", 0x00ff00},
+		{"0000ff", 0x0000ff},
+		{"red", DefaultEmbedColor},
+		{"#fff", DefaultEmbedColor},
+		{"#gggggg", DefaultEmbedColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Setenv("DISCORD_BOT_TOKEN", "test.bot.token")
+			t.Setenv("EMBED_COLOR", tt.raw)
+
+			// A bad color is only worth a warning, never a refusal to start
+			config, err := configFromEnvironment("")
+			if err != nil {
+				t.Fatalf("configFromEnvironment() error = %v", err)
+			}
+			if config.EmbedColor != tt.want {
+				t.Errorf("EmbedColor = %#06x, want %#06x", config.EmbedColor, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// VerseMessageCommand is the name of the message context-menu action that answers a message with a verse
+const VerseMessageCommand = "Get a verse"
+
+// messageCommands are the context-menu actions shown when right-clicking a message. Discord keeps
+// them apart from slash commands, with no description or options.
+var messageCommands = []*discordgo.ApplicationCommand{
+	{
+		Name: VerseMessageCommand,
+		Type: discordgo.MessageApplicationCommand,
+	},
+}
+
+// handleMessageCommand replies to the right-clicked message with a random verse. The interaction
+// itself is only acknowledged privately, since the verse goes out as a reply to the target message.
+func (b *Bot) handleMessageCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	userID := interactionUserID(i)
+
+	ctx, cancel := context.WithTimeout(withRequestID(withLocale(b.ctx, b.interactionLocale(i)), newRequestID()), b.config.Load().CommandTimeout)
+	defer cancel()
+
+	slog.InfoContext(ctx, "Running message command", "guild_id", i.GuildID, "channel_id", i.ChannelID, "user_id", userID, "command", data.Name, "message_id", data.TargetID)
+	b.countCommand("menu")
+
+	outcome := AuditCompleted
+	defer func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			outcome = AuditTimedOut
+		}
+		b.audit.Record(ctx, userID, i.GuildID, i.ChannelID, data.Name, outcome)
+	}()
+
+	// The action is a way of running !verse, so it follows that command's restrictions
+	if verseCommand, ok := b.findCommand("verse"); ok && b.commandDisabled(i.GuildID, verseCommand) {
+		outcome = AuditDisabled
+		respondEphemeral(s, i, localize(ctx, "command_disabled"))
+		return
+	}
+	if !b.channelAllowed(i.GuildID, i.ChannelID) {
+		outcome = AuditDisabled
+		respondEphemeral(s, i, localize(ctx, "channel_not_allowed"))
+		return
+	}
+	if verseCommand, ok := b.findCommand("verse"); ok && b.inMaintenance(userID, verseCommand) {
+		outcome = AuditMaintenance
+		respondEphemeral(s, i, localize(ctx, "maintenance"))
+		return
+	}
+	if ok, wait := b.verseLimiter.Allow(userID); !ok {
+		respondEphemeral(s, i, rateLimitReply(ctx, wait))
+		return
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deferring interaction", "interaction_id", i.ID, "error", err)
+		return
+	}
+
+	verse, err := b.verses.Random(ctx, b.translation(i.GuildID))
+	if err == nil {
+		b.recent.Remember(userID, verse)
+		err = b.replyWithVerse(ctx, s, i.GuildID, i.ChannelID, data.TargetID, userID, verse)
+	}
+	if err != nil {
+		content := verseErrorReply(ctx, err)
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content}); err != nil {
+			slog.ErrorContext(ctx, "Error editing interaction response", "interaction_id", i.ID, "error", err)
+		}
+		return
+	}
+
+	// The verse is visible in the channel, so the private acknowledgement has nothing left to say
+	if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+		slog.WarnContext(ctx, "Error deleting deferred interaction response", "interaction_id", i.ID, "error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// dailyVerses hands out one verse per translation per calendar day, so everyone who
+// asks on the same day sees the same verse until midnight in the configured timezone.
+type dailyVerses struct {
+	mu       sync.Mutex
+	location func() *time.Location
+	day      string
+	verses   map[string]*BibleVerse
+}
+
+// newDailyVerses creates a daily verse cache whose days roll over at midnight in the location loc
+// returns, asked on every lookup so a config reload moves the rollover too
+func newDailyVerses(loc func() *time.Location) *dailyVerses {
+	return &dailyVerses{
+		location: loc,
+		verses:   make(map[string]*BibleVerse),
+	}
+}
+
+// Get returns today's verse for the translation, fetching it with fetch the first time it is asked for.
+// The lock is held while fetching so concurrent callers cannot end up with different verses.
+func (d *dailyVerses) Get(ctx context.Context, translation string, fetch func(ctx context.Context, translation string) (*BibleVerse, error)) (*BibleVerse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	today := time.Now().In(d.location()).Format(time.DateOnly)
+	if today != d.day {
+		d.day = today
+		clear(d.verses)
+	}
+
+	if verse, ok := d.verses[translation]; ok {
+		return verse, nil
+	}
+
+	verse, err := fetch(ctx, translation)
+	if err != nil {
+		return nil, err
+	}
+	d.verses[translation] = verse
+	return verse, nil
+}
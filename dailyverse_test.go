@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDailyVersesFollowsLocationChange(t *testing.T) {
+	// Etc/GMT+12 and Etc/GMT-14 are 26 hours apart, so they are never on the same date
+	behind, err := time.LoadLocation("Etc/GMT+12")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	ahead, err := time.LoadLocation("Etc/GMT-14")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	loc := behind
+	d := newDailyVerses(func() *time.Location { return loc })
+	fetches := 0
+	fetch := func(ctx context.Context, translation string) (*BibleVerse, error) {
+		fetches++
+		return &BibleVerse{}, nil
+	}
+
+	for range 2 {
+		if _, err := d.Get(context.Background(), "web", fetch); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("fetched %d times on one day, want 1", fetches)
+	}
+
+	// A reload that moves the timezone starts a new day
+	loc = ahead
+	if _, err := d.Get(context.Background(), "web", fetch); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d times after the timezone changed, want 2", fetches)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// channelDebounce stops the same post from going to a channel twice within a window,
+// e.g. when the daily scheduler and a manual !dailyverse collide. Any ID works as the key,
+// so it also stops an edited message from running its command twice.
+type channelDebounce struct {
+	mu     sync.Mutex
+	window time.Duration
+	last   map[string]time.Time
+}
+
+// newChannelDebounce creates a debounce that allows one post per channel per window
+func newChannelDebounce(window time.Duration) *channelDebounce {
+	return &channelDebounce{
+		window: window,
+		last:   make(map[string]time.Time),
+	}
+}
+
+// Allow records a post to channelID and returns true, or returns false if one was made within the window
+func (d *channelDebounce) Allow(channelID string) bool {
+	if d.window <= 0 {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for id, at := range d.last {
+		if now.Sub(at) >= d.window {
+			delete(d.last, id)
+		}
+	}
+
+	if _, recent := d.last[channelID]; recent {
+		return false
+	}
+	d.last[channelID] = now
+	return true
+}
+
+// Release forgets the post Allow recorded for channelID, for when it never made it to Discord,
+// so a retry is not turned away
+func (d *channelDebounce) Release(channelID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.last, channelID)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelDebounce(t *testing.T) {
+	d := newChannelDebounce(time.Minute)
+	if !d.Allow("channel") {
+		t.Fatal("first post was turned away")
+	}
+	if d.Allow("channel") {
+		t.Error("second post within the window was allowed")
+	}
+	if !d.Allow("other") {
+		t.Error("post to another channel was turned away")
+	}
+
+	// A post that failed gives the channel back
+	d.Release("channel")
+	if !d.Allow("channel") {
+		t.Error("post after Release was turned away")
+	}
+	if d.Allow("channel") {
+		t.Error("post right after the retry was allowed")
+	}
+}
+
+func TestChannelDebounceWindowExpires(t *testing.T) {
+	d := newChannelDebounce(20 * time.Millisecond)
+	d.Allow("channel")
+	time.Sleep(30 * time.Millisecond)
+	if !d.Allow("channel") {
+		t.Error("post after the window was turned away")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandDisabled reports whether c is turned off by DISABLED_COMMANDS or by the guild
+func (b *Bot) commandDisabled(guildID string, c *Command) bool {
+	disabled := b.config.Load().DisabledCommands
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		if disabled[name] {
+			return true
+		}
+	}
+	return slices.Contains(b.settings.Get(guildID).DisabledCommands, c.Name)
+}
+
+// toggleableCommand resolves the command named in a !disable or !enable call, replying and
+// returning false when there is none or it may not be turned off
+func (b *Bot) toggleableCommand(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string, usageKey string) (*Command, bool) {
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return nil, false
+	}
+	if len(args) != 1 {
+		b.reply(ctx, s, m, localize(ctx, usageKey, b.prefix(m.GuildID)))
+		return nil, false
+	}
+
+	// Accept the command with or without the prefix, by name or alias
+	name := strings.ToLower(strings.TrimPrefix(args[0], b.prefix(m.GuildID)))
+	c, ok := b.findCommand(name)
+	if !ok {
+		b.reply(ctx, s, m, localize(ctx, "disable_unknown", name))
+		return nil, false
+	}
+
+	// A server that could disable these would have no way back
+	if c.Name == "disable" || c.Name == "enable" {
+		b.reply(ctx, s, m, localize(ctx, "disable_protected", c.Name))
+		return nil, false
+	}
+	return c, true
+}
+
+// handleDisable turns a command off in the current guild
+func (b *Bot) handleDisable(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	c, ok := b.toggleableCommand(ctx, s, m, args, "usage_disable")
+	if !ok {
+		return
+	}
+
+	err := b.settings.Update(m.GuildID, func(gc *GuildConfig) {
+		if !slices.Contains(gc.DisabledCommands, c.Name) {
+			gc.DisabledCommands = append(gc.DisabledCommands, c.Name)
+		}
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error saving disabled commands", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	prefix := b.prefix(m.GuildID)
+	b.reply(ctx, s, m, localize(ctx, "disable_done", prefix+c.Name, prefix, c.Name))
+}
+
+// handleEnable turns a command the guild disabled back on
+func (b *Bot) handleEnable(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	c, ok := b.toggleableCommand(ctx, s, m, args, "usage_enable")
+	if !ok {
+		return
+	}
+
+	err := b.settings.Update(m.GuildID, func(gc *GuildConfig) {
+		gc.DisabledCommands = slices.DeleteFunc(gc.DisabledCommands, func(name string) bool { return name == c.Name })
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error saving disabled commands", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+
+	// The server's list no longer has it, but the operator's still might
+	prefix := b.prefix(m.GuildID)
+	if b.commandDisabled(m.GuildID, c) {
+		b.reply(ctx, s, m, localize(ctx, "disable_global", prefix+c.Name))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "enable_done", prefix+c.Name))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleFeedback forwards the user's message to FEEDBACK_CHANNEL_ID for the bot's maintainers
+func (b *Bot) handleFeedback(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	channelID := b.config.Load().FeedbackChannelID
+	if channelID == "" {
+		b.reply(ctx, s, m, localize(ctx, "feedback_off"))
+		return
+	}
+	if len(args) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "usage_feedback", b.prefix(m.GuildID)))
+		return
+	}
+	if ok, wait := b.feedbackLimiter.Allow(m.Author.ID); !ok {
+		b.reply(ctx, s, m, rateLimitReply(ctx, wait))
+		return
+	}
+
+	text := strings.Join(args, " ")
+	_, err := s.ChannelMessageSendEmbed(channelID, limitEmbed(b.createFeedbackEmbed(s, m, text)))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error forwarding feedback", "channel_id", channelID, "user_id", m.Author.ID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "feedback_failed")))
+		return
+	}
+
+	slog.InfoContext(ctx, "Feedback forwarded", "guild_id", m.GuildID, "user_id", m.Author.ID)
+	b.reply(ctx, s, m, localize(ctx, "feedback_sent"))
+}
+
+// createFeedbackEmbed shows who sent feedback and from where. It is read by maintainers, so it is not localized.
+// limitEmbed cuts overly long feedback down to a single field's worth.
+func (b *Bot) createFeedbackEmbed(s *discordgo.Session, m *discordgo.MessageCreate, text string) *discordgo.MessageEmbed {
+	from := "Direct message"
+	if m.GuildID != "" {
+		from = m.GuildID
+		if guild, err := s.State.Guild(m.GuildID); err == nil {
+			from = fmt.Sprintf("%s (%s)", guild.Name, guild.ID)
+		}
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "Feedback",
+		Color: b.config.Load().EmbedColor,
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    fmt.Sprintf("%s (%s)", m.Author.Username, m.Author.ID),
+			IconURL: m.Author.AvatarURL(""),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Message", Value: text},
+			{Name: "Server", Value: from},
+		},
+		Timestamp: embedTimestamp(time.Now()),
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Verse styles a guild can pick with !style
+const (
+	VerseStyleEmbed = "embed"
+	VerseStylePlain = "plain"
+	VerseStyleImage = "image"
+)
+
+// VerseStyles lists the styles in the order !style shows them
+var VerseStyles = []string{VerseStyleEmbed, VerseStylePlain, VerseStyleImage}
+
+// VerseFormatter renders a verse as a message ready to send. Each style a guild can
+// choose is one implementation, so adding a style does not touch the send paths.
+type VerseFormatter interface {
+	Format(verse *BibleVerse) (*discordgo.MessageSend, error)
+}
+
+// embedFormatter shows the verse in a single embed
+type embedFormatter struct{ b *Bot }
+
+func (f embedFormatter) Format(verse *BibleVerse) (*discordgo.MessageSend, error) {
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{f.b.createVerseEmbed(verse)}}, nil
+}
+
+// plainFormatter writes the verse as message text, for servers that prefer no embeds
+type plainFormatter struct{ b *Bot }
+
+func (f plainFormatter) Format(verse *BibleVerse) (*discordgo.MessageSend, error) {
+	send, _ := embedFormatter(f).Format(verse)
+	return plainTextMessage(send), nil
+}
+
+// imageFormatter shows the verse as an image card
+type imageFormatter struct{ b *Bot }
+
+func (f imageFormatter) Format(verse *BibleVerse) (*discordgo.MessageSend, error) {
+	return f.b.verseCardMessage(verse)
+}
+
+// jsonFormatter shows the verse as pretty-printed JSON for debugging with !verse --json. It is
+// not a style guilds can pick. Passage fields hidden from the API decoding are included.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(verse *BibleVerse) (*discordgo.MessageSend, error) {
+	data, err := json.MarshalIndent(struct {
+		*BibleVerse
+		Reference string        `json:"reference,omitempty"`
+		Verses    []RandomVerse `json:"verses,omitempty"`
+		Truncated bool          `json:"truncated,omitempty"`
+	}{verse, verse.Reference, verse.Verses, verse.Truncated}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	const fenceStart, fenceEnd = "```json\n", "\n```"
+	body := truncate(string(data), MessageContentLimit-len(fenceStart)-len(fenceEnd))
+	return &discordgo.MessageSend{Content: fenceStart + body + fenceEnd}, nil
+}
+
+// formatter returns the VerseFormatter for style, or false if there is no such style
+func (b *Bot) formatter(style string) (VerseFormatter, bool) {
+	switch style {
+	case VerseStyleEmbed:
+		return embedFormatter{b}, true
+	case VerseStylePlain:
+		return plainFormatter{b}, true
+	case VerseStyleImage:
+		return imageFormatter{b}, true
+	default:
+		return nil, false
+	}
+}
+
+// verseStyle returns the style a guild has chosen, falling back to image cards when
+// VERSE_IMAGE is on and embeds otherwise
+func (b *Bot) verseStyle(guildID string) string {
+	if style := b.settings.Get(guildID).VerseStyle; style != "" {
+		return style
+	}
+	if b.config.Load().VerseImage {
+		return VerseStyleImage
+	}
+	return VerseStyleEmbed
+}
+
+// handleStyle shows or changes how the guild's verses are displayed
+func (b *Bot) handleStyle(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		b.reply(ctx, s, m, localize(ctx, "style_current", b.verseStyle(m.GuildID), strings.Join(VerseStyles, ", ")))
+		return
+	}
+	if m.GuildID == "" {
+		b.reply(ctx, s, m, localize(ctx, "guild_only"))
+		return
+	}
+	if !b.isOwner(m.Author.ID) && !isGuildAdmin(s, m) {
+		b.reply(ctx, s, m, localize(ctx, "missing_permission", permissionName(discordgo.PermissionManageServer)))
+		return
+	}
+
+	style := strings.ToLower(args[0])
+	if !slices.Contains(VerseStyles, style) {
+		b.reply(ctx, s, m, localize(ctx, "style_invalid", args[0], strings.Join(VerseStyles, ", ")))
+		return
+	}
+
+	if err := b.settings.Update(m.GuildID, func(gc *GuildConfig) { gc.VerseStyle = style }); err != nil {
+		slog.ErrorContext(ctx, "Error saving verse style", "guild_id", m.GuildID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "settings_failed")))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "style_set", style))
+}
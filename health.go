@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// newHealthServer builds the probe server for container orchestration:
+// /healthz reports whether the Discord session is connected and /readyz whether Ready has fired.
+// Prometheus metrics are served on /metrics when enabled.
+func (b *Bot) newHealthServer(s *discordgo.Session) *http.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.RLock()
+		connected := s.DataReady
+		s.RUnlock()
+		writeProbe(w, connected)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, b.ready.Load())
+	})
+
+	if botMetrics != nil {
+		mux.Handle("/metrics", botMetrics.handler())
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", b.config.Load().HealthPort),
+		Handler:           mux,
+		ReadHeaderTimeout: RequestTimeout,
+	}
+}
+
+// writeProbe answers a probe with 200 when ok and 503 otherwise
+func writeProbe(w http.ResponseWriter, ok bool) {
+	if !ok {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// serveHealth runs the health server in the background and returns it, or nil when HEALTH_PORT is unset
+func (b *Bot) serveHealth(s *discordgo.Session) *http.Server {
+	if b.config.Load().HealthPort == 0 {
+		return nil
+	}
+
+	srv := b.newHealthServer(s)
+	go func() {
+		slog.Info("Health server listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Health server error", "error", err)
+		}
+	}()
+	return srv
+}
+
+// selfTest fetches one random verse in the default translation straight from bible-api.com,
+// without failover, so a broken API or a bad DEFAULT_TRANSLATION shows up at startup
+func (b *Bot) selfTest(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, b.config.Load().CommandTimeout)
+	defer cancel()
+
+	translation := b.translation("")
+	start := time.Now()
+	verse, err := getBibleVerse(ctx, translation)
+	if err != nil {
+		return fmt.Errorf("fetching a %s verse: %w", translation, err)
+	}
+	slog.Info("Startup self-test passed", "translation", translation, "reference", verse.Title(), "duration", time.Since(start))
+	return nil
+}
+
+// shutdownHealth stops the health server if it is running
+func shutdownHealth(srv *http.Server) error {
+	if srv == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("Error shutting down health server", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultLocale is used when neither Discord nor DEFAULT_LOCALE names a locale in the catalog
+const DefaultLocale = "en"
+
+// catalog holds every user-facing reply, keyed by locale and then message key.
+// Messages are fmt format strings; English is the fallback for missing keys.
+var catalog = map[string]map[string]string{
+	"en": {
+		"unknown_command":      "Unknown command. Type %shelp for a list of commands.",
+		"hello":                "Hello! I'm your Bible verse bot. Type %sverse for a random verse!",
+		"pong":                 "Pong! 🏓",
+		"ping_gateway":         "Gateway heartbeat",
+		"ping_round_trip":      "Message round trip",
+		"ping_api":             "Last Bible API call",
+		"ping_no_api":          "None yet",
+		"rate_limited":         "You're going too fast, try again in %ds",
+		"unknown_book":         "I don't know a book called %q.",
+		"unknown_book_suggest": "I don't know a book called %q. Did you mean %s?",
+		"passage_not_found":    "Sorry, I couldn't find that passage.",
+		"passage_too_large":    "That passage is too long for me, try a smaller range.",
+		"unknown_translation":  "Unknown translation. Valid options: %s",
+		"verse_unavailable":    "Sorry, I couldn't retrieve a verse right now.",
+		"verse_timeout":        "The Bible API is taking too long to answer, please try again.",
+		"verse_upstream":       "The Bible API is down right now, please try again later.",
+		"usage_random":         "Usage: %srandom <book>, e.g. %srandom Psalms",
+		"usage_search":         "Usage: %ssearch <keyword>",
+		"translation_current":  "The current translation is %s.",
+		"translation_invalid":  "Unknown translation %q. Valid options: %s",
+		"translation_set":      "Default translation set to %s.",
+		"translations_title":   "Available Translations",
+		"books_title":          "Books of the Bible",
+		"bookmark_nothing":     "There's nothing to bookmark yet. Use %sverse first!",
+		"bookmark_failed":      "Sorry, I couldn't save that bookmark right now.",
+		"bookmark_exists":      "%s is already in your bookmarks.",
+		"bookmark_saved":       "Bookmarked %s 🔖",
+		"bookmarks_failed":     "Sorry, I couldn't load your bookmarks right now.",
+		"bookmarks_empty":      "You have no bookmarks yet. Use %sbookmark after a verse to save it.",
+		"bookmarks_title":      "Your Bookmarks 🔖",
+		"subscribe_done":       "You're subscribed! I'll DM you the verse of the day at %s. Use %sunsubscribe to stop.",
+		"subscribe_exists":     "You're already subscribed. Use %sunsubscribe to stop.",
+		"subscribe_failed":     "Sorry, I couldn't update your subscription right now.",
+		"unsubscribe_done":     "You won't get the daily verse by DM any more.",
+		"unsubscribe_none":     "You aren't subscribed. Use %ssubscribe to get the daily verse by DM.",
+		"subscription_dm":      "Here's today's verse. Reply %sunsubscribe to stop these messages.",
+		"search_failed":        "Sorry, I couldn't search verses right now.",
+		"search_empty":         "No verses found for %q.",
+		"search_title":         "Verses mentioning \"%s\"",
+		"help_title":           "Available Commands",
+		"page_expired":         "This passage has expired, please request it again.",
+		"another_button":       "Another",
+		"version_title":        "Bot Version",
+		"version_version":      "Version",
+		"version_commit":       "Commit",
+		"version_built":        "Built",
+		"not_allowed":          "You're not allowed to do that",
+		"missing_permission":   "You need the **%s** permission to do that.",
+		"internal_error":       "Something went wrong handling that, sorry. It has been logged.",
+		"guild_only":           "That only works in a server.",
+		"usage_setprefix":      "Usage: %ssetprefix <prefix>",
+		"prefix_invalid":       "A prefix must be 1 to %d characters with no spaces or mentions.",
+		"settings_failed":      "Sorry, I couldn't save that setting right now.",
+		"request_ref":          "Reference: %s",
+		"dailychannel_set":     "The daily verse will be posted in <#%s>.",
+		"dailychannel_off":     "The daily verse is off for this server.",
+		"prefix_set":           "Command prefix set to %s",
+		"reload_failed":        "Reload failed, keeping the current configuration: %v",
+		"reload_done":          "Configuration reloaded. Cache, rate limit, sharding and health settings apply after a restart.",
+		"stats_title":          "Bot Stats",
+		"stats_uptime":         "Uptime",
+		"stats_guilds":         "Servers",
+		"stats_users":          "Users",
+		"stats_memory":         "Memory",
+		"stats_memory_value":   "%.1f MiB in use, %.1f MiB from the OS",
+		"stats_commands":       "Commands Run",
+		"stats_top":            "Top Commands",
+		"stats_maintenance":    "Maintenance",
+		"stats_on":             "On",
+		"stats_off":            "Off",
+		"command_disabled":     "That command is disabled here.",
+		"channel_not_allowed":  "Commands can't be used in this channel.",
+		"maintenance":          "The bot is under maintenance, back soon.",
+		"maintenance_on":       "Maintenance mode is on. Only admin commands run until %smaintenance off.",
+		"maintenance_off":      "Maintenance mode is off. All commands are available again.",
+		"usage_maintenance":    "Usage: %smaintenance <on|off>",
+		"usage_disable":        "Usage: %sdisable <command>",
+		"usage_enable":         "Usage: %senable <command>",
+		"disable_unknown":      "There is no command called %q.",
+		"disable_protected":    "%s can't be disabled.",
+		"disable_global":       "%s is disabled for every server by the bot's operator.",
+		"disable_done":         "%s is now disabled here. Use %senable %s to turn it back on.",
+		"enable_done":          "%s is enabled here.",
+		"usage_compare":        "Usage: %scompare <reference> <translation> <translation>..., e.g. %scompare John 3:16 kjv web",
+		"compare_too_many":     "You can compare up to %d translations at once.",
+		"usage_feedback":       "Usage: %sfeedback <message>",
+		"feedback_off":         "Feedback isn't set up for this bot.",
+		"feedback_failed":      "Sorry, I couldn't send your feedback right now.",
+		"feedback_sent":        "Thanks, your feedback was sent!",
+		"usage_mention":        "Usage: %smention <on|off>",
+		"mention_on":           "Verse replies will now mention whoever asked.",
+		"mention_off":          "Verse replies will no longer mention whoever asked.",
+		"style_current":        "Verses here are shown as: **%s**. Available styles: %s",
+		"style_invalid":        "Unknown style %q. Valid options: %s",
+		"style_set":            "Verses will now be shown as: **%s**.",
+		"audio_off":            "Audio isn't set up for this bot, so here's the text.",
+		"audio_failed":         "I couldn't create audio for this verse, so here's the text.",
+		"streak_none":          "You don't have a streak yet. Read a verse with %sverse to start one!",
+		"streak":               "🔥 Current streak: %d days. Longest: %d days.",
+	},
+	"es": {
+		"unknown_command":      "Comando desconocido. Escribe %shelp para ver la lista de comandos.",
+		"hello":                "¡Hola! Soy tu bot de versículos bíblicos. ¡Escribe %sverse para un versículo aleatorio!",
+		"pong":                 "¡Pong! 🏓",
+		"ping_gateway":         "Latido del gateway",
+		"ping_round_trip":      "Ida y vuelta del mensaje",
+		"ping_api":             "Última llamada a la API bíblica",
+		"ping_no_api":          "Ninguna todavía",
+		"rate_limited":         "Vas demasiado rápido, inténtalo de nuevo en %ds",
+		"unknown_book":         "No conozco un libro llamado %q.",
+		"unknown_book_suggest": "No conozco un libro llamado %q. ¿Quisiste decir %s?",
+		"passage_not_found":    "Lo siento, no pude encontrar ese pasaje.",
+		"passage_too_large":    "Ese pasaje es demasiado largo para mí, prueba con un rango más pequeño.",
+		"unknown_translation":  "Traducción desconocida. Opciones válidas: %s",
+		"verse_unavailable":    "Lo siento, no pude obtener un versículo en este momento.",
+		"verse_timeout":        "La API de la Biblia está tardando demasiado en responder, inténtalo de nuevo.",
+		"verse_upstream":       "La API de la Biblia no está disponible ahora, inténtalo más tarde.",
+		"usage_random":         "Uso: %srandom <libro>, p. ej. %srandom Psalms",
+		"usage_search":         "Uso: %ssearch <palabra>",
+		"translation_current":  "La traducción actual es %s.",
+		"translation_invalid":  "Traducción desconocida %q. Opciones válidas: %s",
+		"translation_set":      "Traducción predeterminada cambiada a %s.",
+		"translations_title":   "Traducciones disponibles",
+		"books_title":          "Libros de la Biblia",
+		"bookmark_nothing":     "Todavía no hay nada que guardar. ¡Usa %sverse primero!",
+		"bookmark_failed":      "Lo siento, no pude guardar ese marcador en este momento.",
+		"bookmark_exists":      "%s ya está en tus marcadores.",
+		"bookmark_saved":       "%s guardado 🔖",
+		"bookmarks_failed":     "Lo siento, no pude cargar tus marcadores en este momento.",
+		"bookmarks_empty":      "Aún no tienes marcadores. Usa %sbookmark después de un versículo para guardarlo.",
+		"bookmarks_title":      "Tus marcadores 🔖",
+		"subscribe_done":       "¡Te has suscrito! Te enviaré el versículo del día por DM a las %s. Usa %sunsubscribe para dejar de recibirlo.",
+		"subscribe_exists":     "Ya estás suscrito. Usa %sunsubscribe para dejar de recibirlo.",
+		"subscribe_failed":     "Lo siento, no pude actualizar tu suscripción en este momento.",
+		"unsubscribe_done":     "Ya no recibirás el versículo del día por DM.",
+		"unsubscribe_none":     "No estás suscrito. Usa %ssubscribe para recibir el versículo del día por DM.",
+		"subscription_dm":      "Este es el versículo de hoy. Responde %sunsubscribe para dejar de recibir estos mensajes.",
+		"search_failed":        "Lo siento, no pude buscar versículos en este momento.",
+		"search_empty":         "No se encontraron versículos para %q.",
+		"search_title":         "Versículos que mencionan \"%s\"",
+		"help_title":           "Comandos disponibles",
+		"page_expired":         "Este pasaje ha caducado, pídelo de nuevo.",
+		"another_button":       "Otro",
+		"version_title":        "Versión del bot",
+		"version_version":      "Versión",
+		"version_commit":       "Commit",
+		"version_built":        "Compilado",
+		"not_allowed":          "No tienes permiso para hacer eso",
+		"missing_permission":   "Necesitas el permiso **%s** para hacer eso.",
+		"internal_error":       "Algo salió mal al procesar eso, lo siento. Ha quedado registrado.",
+		"guild_only":           "Eso solo funciona en un servidor.",
+		"usage_setprefix":      "Uso: %ssetprefix <prefijo>",
+		"prefix_invalid":       "Un prefijo debe tener de 1 a %d caracteres, sin espacios ni menciones.",
+		"settings_failed":      "Lo siento, no pude guardar ese ajuste en este momento.",
+		"request_ref":          "Referencia: %s",
+		"dailychannel_set":     "El versículo del día se publicará en <#%s>.",
+		"dailychannel_off":     "El versículo del día está desactivado en este servidor.",
+		"prefix_set":           "Prefijo de comandos cambiado a %s",
+		"reload_failed":        "Error al recargar, se mantiene la configuración actual: %v",
+		"reload_done":          "Configuración recargada. Los ajustes de caché, límite de uso, shards y salud se aplican tras reiniciar.",
+		"stats_title":          "Estadísticas del bot",
+		"stats_uptime":         "Tiempo activo",
+		"stats_guilds":         "Servidores",
+		"stats_users":          "Usuarios",
+		"stats_memory":         "Memoria",
+		"stats_memory_value":   "%.1f MiB en uso, %.1f MiB del sistema",
+		"stats_commands":       "Comandos ejecutados",
+		"stats_top":            "Comandos más usados",
+		"stats_maintenance":    "Mantenimiento",
+		"stats_on":             "Activado",
+		"stats_off":            "Desactivado",
+		"command_disabled":     "Ese comando está desactivado aquí.",
+		"channel_not_allowed":  "Los comandos no se pueden usar en este canal.",
+		"maintenance":          "El bot está en mantenimiento, volvemos pronto.",
+		"maintenance_on":       "El modo de mantenimiento está activado. Solo se ejecutan comandos de administración hasta %smaintenance off.",
+		"maintenance_off":      "El modo de mantenimiento está desactivado. Todos los comandos vuelven a estar disponibles.",
+		"usage_maintenance":    "Uso: %smaintenance <on|off>",
+		"usage_disable":        "Uso: %sdisable <comando>",
+		"usage_enable":         "Uso: %senable <comando>",
+		"disable_unknown":      "No hay ningún comando llamado %q.",
+		"disable_protected":    "%s no se puede desactivar.",
+		"disable_global":       "El operador del bot ha desactivado %s en todos los servidores.",
+		"disable_done":         "%s está desactivado aquí. Usa %senable %s para volver a activarlo.",
+		"enable_done":          "%s está activado aquí.",
+		"usage_compare":        "Uso: %scompare <referencia> <traducción> <traducción>..., p. ej. %scompare John 3:16 kjv web",
+		"compare_too_many":     "Puedes comparar hasta %d traducciones a la vez.",
+		"usage_feedback":       "Uso: %sfeedback <mensaje>",
+		"feedback_off":         "Los comentarios no están configurados para este bot.",
+		"feedback_failed":      "Lo siento, no pude enviar tus comentarios en este momento.",
+		"feedback_sent":        "¡Gracias, tus comentarios se han enviado!",
+		"usage_mention":        "Uso: %smention <on|off>",
+		"mention_on":           "Las respuestas con versículos ahora mencionarán a quien los pidió.",
+		"mention_off":          "Las respuestas con versículos ya no mencionarán a quien los pidió.",
+		"style_current":        "Aquí los versículos se muestran como: **%s**. Estilos disponibles: %s",
+		"style_invalid":        "Estilo desconocido %q. Opciones válidas: %s",
+		"style_set":            "Los versículos ahora se mostrarán como: **%s**.",
+		"audio_off":            "El audio no está configurado en este bot, así que aquí está el texto.",
+		"audio_failed":         "No pude crear el audio de este versículo, así que aquí está el texto.",
+		"streak_none":          "Aún no tienes una racha. ¡Lee un versículo con %sverse para empezar una!",
+		"streak":               "🔥 Racha actual: %d días. Más larga: %d días.",
+	},
+}
+
+// localeKey is the context key under which the request locale is stored
+type localeKey struct{}
+
+// withLocale returns a context carrying the locale replies should use
+func withLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// localize formats the message for key in the context's locale, falling back to English
+func localize(ctx context.Context, key string, args ...any) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	format, ok := catalog[locale][key]
+	if !ok {
+		format = catalog[DefaultLocale][key]
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// resolveLocale picks the first candidate the catalog supports, then DEFAULT_LOCALE, then English.
+// Discord locales like "es-ES" match on their language part.
+func (b *Bot) resolveLocale(candidates ...string) string {
+	for _, candidate := range append(candidates, b.config.Load().DefaultLocale) {
+		lang, _, _ := strings.Cut(strings.ToLower(candidate), "-")
+		if _, ok := catalog[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// messageLocale resolves the locale for a message from its guild's preferred locale
+func (b *Bot) messageLocale(s *discordgo.Session, m *discordgo.MessageCreate) string {
+	return b.guildLocale(s, m.GuildID)
+}
+
+// guildLocale resolves the locale for events in a guild, such as reactions, from its preferred locale
+func (b *Bot) guildLocale(s *discordgo.Session, guildID string) string {
+	if guildID != "" {
+		if guild, err := s.State.Guild(guildID); err == nil {
+			return b.resolveLocale(guild.PreferredLocale)
+		}
+	}
+	return b.resolveLocale()
+}
+
+// interactionLocale resolves the locale for an interaction from the user's and then the guild's locale
+func (b *Bot) interactionLocale(i *discordgo.InteractionCreate) string {
+	candidates := []string{string(i.Locale)}
+	if i.GuildLocale != nil {
+		candidates = append(candidates, string(*i.GuildLocale))
+	}
+	return b.resolveLocale(candidates...)
+}
@@ -1,240 +1,496 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/joho/godotenv"
+	"github.com/rivo/uniseg"
 )
 
 // Configuration constants
 const (
-	Prefix         = "!"
-	BibleAPIURL    = "https://bible-api.com/data/web/random"
-	RequestTimeout = 10 * time.Second
-	EnvFileName    = ".env"
+	DefaultPrefix      = "!"
+	ProjectURL         = "https://github.com/room-temperature-miso-soup/verseondemanddiscord"
+	BibleAPIBase       = "https://bible-api.com"
+	SearchAPIBase      = "https://bolls.life"
+	SearchTranslation  = "KJV"
+	MaxSearchResults   = 5
+	DefaultTranslation = "web"
+	RequestTimeout     = 10 * time.Second
+	FetchDeadline      = 15 * time.Second
+	MaxFetchAttempts   = 3
+	RetryBaseDelay     = 200 * time.Millisecond
+	FailoverTimeout    = 5 * time.Second
+	ShutdownTimeout    = 5 * time.Second
+
+	DefaultReferenceCacheTTL  = time.Hour
+	DefaultCommandTimeout     = 15 * time.Second
+	TranslationListTTL        = 24 * time.Hour
+	DefaultVerseRateLimit     = 5
+	DefaultVerseRateWindow    = 30 * time.Second
+	DefaultDailyVerseTime     = "08:00"
+	DefaultDailyVerseDebounce = 5 * time.Minute
+	MinAutoVerseInterval      = time.Minute
+	DefaultBookmarksFile      = "bookmarks.json"
+	DefaultSettingsFile       = "settings.json"
+	DefaultSubscriptionsFile  = "subscriptions.json"
+	DefaultMaxDMFailures      = 3
+	DefaultEmbedColor         = 0x3498db
+	DefaultMaxResponseBytes   = 256 * 1024
+	DefaultMaxRangeVerses     = 20
+	DefaultMaxAPIRequests     = 10
+	DefaultBotStatusInterval  = 5 * time.Minute
+	DefaultAuditLogMaxSizeMB  = 10
+	DefaultAuditLogMaxBackups = 5
+
+	// Initial Discord connection retries
+	OpenAttempts   = 5
+	OpenRetryDelay = time.Second
+
+	// Discord embed size limits, in characters
+	EmbedTitleLimit       = 256
+	EmbedDescriptionLimit = 4096
+	EmbedFieldNameLimit   = 256
+	EmbedFieldValueLimit  = 1024
+	EmbedFooterLimit      = 2048
+	EmbedAuthorLimit      = 256
+
+	// Long passages are split into pages of PageSize runes, kept navigable for PaginatorTTL
+	PageSize     = 3500
+	PaginatorTTL = 10 * time.Minute
+	EnvFileName  = ".env"
+
+	// Verse messages can be bookmarked by reacting with ⭐ for PostedVerseTTL after they are sent
+	PostedVerseTTL = 24 * time.Hour
+
+	// Bulk sends wait in a queue of SendQueueSize and retry rate-limited sends up to MaxSendAttempts times
+	SendQueueSize   = 100
+	MaxSendAttempts = 3
+
+	// Daily verse DMs go out DMBatchSize at a time with DMBatchDelay between batches
+	DMBatchSize  = 25
+	DMBatchDelay = 5 * time.Second
+
+	// Process exit codes, so orchestrators can tell a clean stop from a troubled one
+	ExitOK              = 0
+	ExitStartupFailure  = 1
+	ExitShutdownError   = 2
+	ExitShutdownTimeout = 3
+
+	// !compare takes up to MaxCompareTranslations, fetching CompareConcurrency of them at once
+	MaxCompareTranslations = 5
+	CompareConcurrency     = 3
+
+	// With HANDLE_EDITS on, messages edited into commands are run if sent within EditedCommandWindow
+	EditedCommandWindow = 10 * time.Minute
+
+	// Spoken verses are requested from TTS_ENDPOINT in TTSFormat and must fit in a Discord upload
+	TTSFormat     = "mp3"
+	MaxAudioBytes = 8 << 20
+
+	// !stats lists the StatsTopCommands most used commands
+	StatsTopCommands = 5
+
+	// Each user may send FeedbackRateLimit !feedback messages per FeedbackRateWindow
+	FeedbackRateLimit  = 2
+	FeedbackRateWindow = 10 * time.Minute
 )
 
-// AppConfig holds application-wide configuration
-type AppConfig struct {
-	DiscordToken string
-	Debug        bool
+// configureLogging sets up logging based on configuration
+func configureLogging(debug bool) {
+	var handler slog.Handler
+	if debug {
+		// Verbose, human-readable logging for debugging
+		handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug})
+	} else {
+		// JSON logging for production log pipelines
+		handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
+	}
+	slog.SetDefault(slog.New(requestIDHandler{handler}))
 }
 
-// BibleVerse represents the structured data from the Bible API
-type BibleVerse struct {
-	Translation map[string]interface{} `json:"translation"`
-	RandomVerse map[string]interface{} `json:"random_verse"`
+// fatal logs an error and exits the process with ExitStartupFailure
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(ExitStartupFailure)
 }
 
-// loadConfiguration handles loading and validating application configuration
-func loadConfiguration() (*AppConfig, error) {
-	// Load environment variables from .env file
-	err := godotenv.Load(EnvFileName)
-	if err != nil {
-		return nil, fmt.Errorf("error loading %s file: %w", EnvFileName, err)
-	}
+// readyHandler logs when the bot successfully connects to Discord and marks the bot ready.
+// Guilds are logged by guildCreate, since Ready lists them before their details arrive.
+func (b *Bot) readyHandler(s *discordgo.Session, event *discordgo.Ready) {
+	b.ready.Store(true)
 
-	// Retrieve and validate required configuration values
-	config := &AppConfig{
-		DiscordToken: os.Getenv("DISCORD_BOT_TOKEN"),
-		Debug:        os.Getenv("DEBUG") == "true",
-	}
+	logBuildInfo()
+	slog.Info("Bot connected", "username", s.State.User.Username, "discriminator", s.State.User.Discriminator, "user_id", s.State.User.ID)
+	b.updatePresence(s)
+}
 
-	// Validate critical configuration
-	if config.DiscordToken == "" {
-		return nil, fmt.Errorf("DISCORD_BOT_TOKEN is required in %s", EnvFileName)
+// guildCreate logs each guild the bot is connected to, once per guild however often Discord resends it
+func (b *Bot) guildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
+	if g.Unavailable {
+		return
 	}
-
-	return config, nil
+	if _, seen := b.guilds.LoadOrStore(g.ID, struct{}{}); seen {
+		return
+	}
+	slog.Info("Connected to guild", "guild_name", g.Name, "guild_id", g.ID)
 }
 
-// configureLogging sets up logging based on configuration
-func configureLogging(debug bool) {
-	if debug {
-		// Verbose logging for debugging
-		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile | log.Lmicroseconds)
-	} else {
-		// Minimal production logging
-		log.SetFlags(log.Ldate | log.Ltime)
+// SafeSend sends a message to the specified channel with error handling. Content over
+// Discord's length limit goes out as several messages, split between lines.
+func SafeSend(s *discordgo.Session, channelID, content string) {
+	for _, part := range splitMessage(content, MessageContentLimit) {
+		if _, err := s.ChannelMessageSend(channelID, part); err != nil {
+			slog.Error("Error sending message", "channel_id", channelID, "error", err)
+			return
+		}
 	}
 }
 
-// readyHandler logs when the bot successfully connects to Discord and sends a hello message
-func readyHandler(s *discordgo.Session, event *discordgo.Ready) {
-	log.Printf("Bot connected as %s#%s (ID: %s)", s.State.User.Username, s.State.User.Discriminator, s.State.User.ID)
-	for _, guild := range s.State.Guilds {
-		log.Printf("Connected to guild: %s (ID: %s)", guild.Name, guild.ID)
+// SafeSendEmbed sends an embedded message with error handling, as plain text if the bot may not embed there
+func SafeSendEmbed(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed) {
+	_, err := s.ChannelMessageSendEmbed(channelID, limitEmbed(embed))
+	if err != nil && isMissingEmbedPermission(s, channelID, err) {
+		slog.Warn("Missing permission to embed in channel, sending plain text", "channel_id", channelID)
+		SafeSend(s, channelID, embedPlainText(embed))
+		return
+	}
+	if err != nil {
+		slog.Error("Embed send error", "channel_id", channelID, "error", err)
 	}
 }
 
-// SafeSend sends a message to the specified channel with error handling
-func SafeSend(s *discordgo.Session, channelID, content string) {
-	_, err := s.ChannelMessageSend(channelID, content)
+// reply answers a command in its channel, DMing the author if the bot may not post there
+func (b *Bot) reply(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, content string) {
+	_, err := b.replyMessage(ctx, s, m, &discordgo.MessageSend{Content: content})
 	if err != nil {
-		log.Printf("Error sending message to channel %s: %v", channelID, err)
+		slog.ErrorContext(ctx, "Error sending reply", "channel_id", m.ChannelID, "user_id", m.Author.ID, "error", err)
 	}
 }
 
-// SafeSendEmbed sends an embedded message with error handling
-func SafeSendEmbed(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed) {
-	_, err := s.ChannelMessageSendEmbed(channelID, embed)
+// replyEmbed answers a command with an embed, DMing the author if the bot may not post there
+func (b *Bot) replyEmbed(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, embed *discordgo.MessageEmbed) {
+	_, err := b.replyMessage(ctx, s, m, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{limitEmbed(embed)},
+	})
 	if err != nil {
-		log.Printf("Embed send error in channel %s: %v", channelID, err)
+		slog.ErrorContext(ctx, "Embed reply error", "channel_id", m.ChannelID, "user_id", m.Author.ID, "error", err)
 	}
 }
 
-// getBibleVerse fetches a random Bible verse with robust error handling
-func getBibleVerse() (*BibleVerse, error) {
-	client := &http.Client{
-		Timeout: RequestTimeout,
+// replyMessage sends msg in answer to m, threaded under it as a Discord reply when REPLY_REFERENCES is on.
+// A soft reference is used so the answer still goes out if the command message was deleted meanwhile.
+func (b *Bot) replyMessage(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	if b.config.Load().ReplyReferences {
+		msg.Reference = m.SoftReference()
 	}
+	return sendWithDMFallback(ctx, s, m.ChannelID, m.Author.ID, msg)
+}
 
-	resp, err := client.Get(BibleAPIURL)
-	if err != nil {
-		return nil, fmt.Errorf("bible verse API request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// sendWithDMFallback sends msg to channelID. If Discord refuses because the bot lacks permission
+// there, the message is DMed to userID instead with an explanation. An empty userID disables the fallback.
+func sendWithDMFallback(ctx context.Context, s *discordgo.Session, channelID, userID string, msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	sent, err := s.ChannelMessageSendComplex(channelID, msg)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bible verse API returned status: %d", resp.StatusCode)
+	// A channel that only lacks Embed Links still gets the reply, as plain text
+	if err != nil && len(msg.Embeds) > 0 && isMissingEmbedPermission(s, channelID, err) {
+		slog.WarnContext(ctx, "Missing permission to embed in channel, sending plain text", "channel_id", channelID)
+		rewindFiles(msg)
+		return s.ChannelMessageSendComplex(channelID, plainTextMessage(msg))
+	}
+	if err == nil || userID == "" || !isPermissionError(err) {
+		return sent, err
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024)) // 10KB limit
-	if err != nil {
-		return nil, fmt.Errorf("error reading API response: %w", err)
+	slog.WarnContext(ctx, "Missing permission to post in channel, falling back to DM", "channel_id", channelID, "user_id", userID, "error", err)
+	dm, dmErr := s.UserChannelCreate(userID)
+	if dmErr != nil {
+		return nil, fmt.Errorf("%w (DM fallback failed: %v)", err, dmErr)
 	}
 
-	var verse BibleVerse
-	if err := json.Unmarshal(body, &verse); err != nil {
-		return nil, fmt.Errorf("failed to parse verse data: %w", err)
+	// A reply reference to a server message is meaningless in a DM
+	explained := *msg
+	explained.Reference = nil
+	rewindFiles(&explained)
+	explained.Content = strings.TrimSpace(fmt.Sprintf("I don't have permission to post in <#%s>, so here's my reply.\n%s", channelID, msg.Content))
+	sent, dmErr = s.ChannelMessageSendComplex(dm.ID, &explained)
+	if dmErr != nil {
+		return nil, fmt.Errorf("%w (DM fallback failed: %v)", err, dmErr)
 	}
+	return sent, nil
+}
 
-	return &verse, nil
+// rewindFiles seeks msg's attachments back to the start so a failed send can be retried
+func rewindFiles(msg *discordgo.MessageSend) {
+	for _, f := range msg.Files {
+		if seeker, ok := f.Reader.(io.Seeker); ok {
+			_, _ = seeker.Seek(0, io.SeekStart)
+		}
+	}
 }
 
-// createVerseEmbed generates a rich, informative Discord embed
-func createVerseEmbed(verse *BibleVerse) *discordgo.MessageEmbed {
-	var builder strings.Builder
+// isPermissionError reports whether err is Discord rejecting a request with 403 Forbidden
+func isPermissionError(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusForbidden
+}
 
-	builder.WriteString("**Translation Details:**\n")
-	for key, value := range verse.Translation {
-		builder.WriteString(fmt.Sprintf("- %s: %v\n", key, value))
+// truncate shortens s to at most max runes, marking the cut with an ellipsis. It cuts at the
+// last word boundary that fits, or mid-word when there is none, but never inside a grapheme
+// cluster, so accented letters and emoji such as 👍🏽 or flags stay whole.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
 	}
 
-	builder.WriteString("\n**Random Verse:**\n")
-	for key, value := range verse.RandomVerse {
-		builder.WriteString(fmt.Sprintf("- %s: %v\n", key, value))
+	// Leave room for the ellipsis, then find the last cluster and space that fit
+	budget := max - 1
+	cut, wordCut := 0, 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		cluster := g.Runes()
+		start, end := g.Positions()
+		if unicode.IsSpace(cluster[0]) {
+			wordCut = start
+		}
+		if budget -= len(cluster); budget < 0 {
+			break
+		}
+		cut = end
 	}
 
-	return &discordgo.MessageEmbed{
-		Title:       "Daily Bible Verse 📖",
-		Description: builder.String(),
-		Color:       0x3498db,
-		Timestamp:   time.Now().Format(time.RFC3339),
+	if wordCut > 0 {
+		cut = wordCut
 	}
+	return strings.TrimRightFunc(s[:cut], unicode.IsSpace) + "…"
 }
 
-// messageCreate handles incoming Discord messages dynamically using message context
-func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
-	// Ignore messages from the bot itself
-	if m.Author.ID == s.State.User.ID {
-		return
+// limitEmbed truncates embed text to Discord's size limits so the API does not reject it
+func limitEmbed(embed *discordgo.MessageEmbed) *discordgo.MessageEmbed {
+	embed.Title = truncate(embed.Title, EmbedTitleLimit)
+	embed.Description = truncate(embed.Description, EmbedDescriptionLimit)
+	for _, field := range embed.Fields {
+		field.Name = truncate(field.Name, EmbedFieldNameLimit)
+		field.Value = truncate(field.Value, EmbedFieldValueLimit)
 	}
-
-	// Log message details in the terminal
-	log.Printf("Message received in channel %s from %s: %s", m.ChannelID, m.Author.Username, m.Content)
-
-	// Check if the message starts with the command prefix
-	if !strings.HasPrefix(m.Content, Prefix) {
-		return
+	if embed.Footer != nil {
+		embed.Footer.Text = truncate(embed.Footer.Text, EmbedFooterLimit)
 	}
-
-	// Extract command and arguments
-	content := strings.TrimPrefix(m.Content, Prefix)
-	parts := strings.Fields(content)
-	if len(parts) == 0 {
-		return
+	if embed.Author != nil {
+		embed.Author.Name = truncate(embed.Author.Name, EmbedAuthorLimit)
 	}
+	return embed
+}
 
-	command := parts[0]
-
-	// Handle different commands
-	switch command {
-	case "hello":
-		// Respond dynamically to the channel the message was received from
-		SafeSend(s, m.ChannelID, "Hello! I'm your Bible verse bot. Type !verse for a random verse!")
+// openWithRetry opens the Discord session, retrying with exponential backoff.
+// discordgo reconnects on its own once connected, so only the initial open needs this.
+func openWithRetry(dg *discordgo.Session, attempts int, delay time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = dg.Open(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
 
-	case "ping":
-		SafeSend(s, m.ChannelID, "Pong! 🏓")
+		slog.Warn("Discord connection attempt failed, retrying", "attempt", attempt, "max_attempts", attempts, "delay", delay, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
 
-	case "verse":
-		// Fetch a random Bible verse
-		verse, err := getBibleVerse()
+// configureSharding sets the session's shard, asking Discord for the recommended shard count when auto-sharding
+func configureSharding(dg *discordgo.Session, config *AppConfig) error {
+	count := config.ShardCount
+	if config.ShardAuto {
+		gateway, err := dg.GatewayBot()
 		if err != nil {
-			log.Printf("Verse retrieval error: %v", err)
-			SafeSend(s, m.ChannelID, "Sorry, I couldn't retrieve a verse right now.")
-			return
+			return fmt.Errorf("cannot fetch recommended shard count: %w", err)
 		}
-
-		// Create and send an embedded message with the Bible verse
-		embed := createVerseEmbed(verse)
-		SafeSendEmbed(s, m.ChannelID, embed)
-
-	default:
-		// Handle unknown commands
-		SafeSend(s, m.ChannelID, "Unknown command. Try !hello, !ping, or !verse")
+		count = gateway.Shards
+	}
+	if count <= 1 {
+		return nil
+	}
+	if config.ShardID >= count {
+		return fmt.Errorf("SHARD_ID %d is out of range for %d shards", config.ShardID, count)
 	}
+
+	dg.ShardID = config.ShardID
+	dg.ShardCount = count
+	slog.Info("Sharding enabled", "shard_id", dg.ShardID, "shard_count", dg.ShardCount)
+	return nil
 }
 
 func main() {
+	startTime = time.Now()
+	code := run()
+	slog.Info("Shutdown complete", "runtime", time.Since(startTime).Round(time.Second).String(), "exit_code", code)
+	os.Exit(code)
+}
+
+// run starts the bot and blocks until a termination signal, returning the exit code that
+// describes how cleanly it shut down. Startup failures exit directly through fatal.
+func run() (code int) {
+	envFile := flag.String("env", "", "path to an env file (defaults to $ENV_FILE or "+EnvFileName+")")
+	flag.Parse()
+
 	// Load application configuration
-	config, err := loadConfiguration()
+	config, err := loadConfiguration(*envFile)
 	if err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		fatal("Configuration error", "error", err)
 	}
 
 	// Configure logging based on debug setting
 	configureLogging(config.Debug)
 
+	// API responses larger than this are rejected rather than truncated
+	maxResponseBytes = int64(config.MaxResponseBytes)
+	apiRequests = newSemaphore(config.MaxConcurrentRequests)
+	userAgent = config.UserAgent
+
+	// Metrics are only collected when there is a port to serve them on
+	if config.HealthPort != 0 {
+		botMetrics = newMetrics()
+	}
+
 	// Create Discord session
 	dg, err := discordgo.New("Bot " + config.DiscordToken)
 	if err != nil {
-		log.Fatalf("Failed to create Discord session: %v", err)
+		fatal("Failed to create Discord session", "error", err)
+	}
+	dg.Identify.Intents = config.Intents
+
+	// Run as one shard of many when sharding is configured
+	if err := configureSharding(dg, config); err != nil {
+		fatal("Sharding configuration error", "error", err)
 	}
 
+	// Shutdown context is cancelled once a termination signal arrives
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Load saved bookmarks
+	bookmarks, err := newJSONBookmarkStore(config.BookmarksFile)
+	if err != nil {
+		fatal("Bookmark store error", "error", err)
+	}
+
+	// Load what each guild has customised, along with reading streaks
+	settings, err := newJSONSettingsStore(config.SettingsFile)
+	if err != nil {
+		fatal("Settings store error", "error", err)
+	}
+
+	// Commands are audited to their own rotating file when AUDIT_LOG_FILE is set
+	audit := newAuditLog(config)
+	defer audit.Close()
+
+	// Load daily verse DM subscribers
+	subscriptions, err := newJSONSubscriptionStore(config.SubscriptionsFile)
+	if err != nil {
+		fatal("Subscription store error", "error", err)
+	}
+
+	// Create the bot that owns command state and configuration
+	bot := newBot(ctx, config, newFailoverProvider(), bookmarks, settings, subscriptions, audit)
+
 	// Register event handlers
-	dg.AddHandler(readyHandler)  // Logs when the bot connects
-	dg.AddHandler(messageCreate) // Handles incoming messages
+	dg.AddHandler(bot.readyHandler)       // Logs when the bot connects
+	dg.AddHandler(bot.guildCreate)        // Logs each guild as its details arrive
+	dg.AddHandler(bot.messageCreate)      // Handles incoming messages
+	dg.AddHandler(bot.interactionCreate)  // Handles slash commands
+	dg.AddHandler(bot.messageReactionAdd) // Answers 📖 reactions with a verse
+	dg.AddHandler(bot.messageUpdate)      // Runs commands added by editing, if enabled
+
+	// Serve health probes alongside the session
+	health := bot.serveHealth(dg)
+	defer func() {
+		if err := shutdownHealth(health); err != nil && code == ExitOK {
+			code = ExitShutdownError
+		}
+	}()
 
-	// Open WebSocket connection to Discord
-	err = dg.Open()
+	// Open WebSocket connection to Discord, retrying transient failures
+	err = openWithRetry(dg, OpenAttempts, OpenRetryDelay)
 	if err != nil {
-		log.Fatalf("Cannot open Discord connection: %v", err)
+		fatal("Cannot open Discord connection", "error", err)
 	}
 	defer func() {
 		err := dg.Close()
 		if err != nil {
-			log.Printf("Error closing Discord connection: %v", err)
+			slog.Error("Error closing Discord connection", "error", err)
+			if code == ExitOK {
+				code = ExitShutdownError
+			}
 		}
 	}()
 
+	// Check the Bible API works before going live, failing the deploy if required
+	if config.StartupSelfTest {
+		if err := bot.selfTest(ctx); err != nil {
+			if config.StartupSelfTestRequired {
+				slog.Error("Startup self-test failed", "error", err)
+				return ExitStartupFailure
+			}
+			slog.Warn("Startup self-test failed, continuing", "error", err)
+		}
+	}
+
+	// Application-wide work only runs on the first shard so it isn't repeated per shard
+	if dg.ShardID == 0 {
+		// Register slash and message commands globally, or on a single guild when testing
+		if err := bot.registerCommands(dg); err != nil {
+			slog.Error("Command registration error", "error", err)
+		}
+
+		// Post the daily verse and the interval auto verse until shutdown
+		go bot.runDailyVerse(ctx, dg)
+		go bot.runAutoVerse(ctx, dg)
+	}
+
+	// Each shard has its own presence, rotated until shutdown
+	go bot.rotatePresence(ctx, dg)
+
 	// Log startup information
-	log.Println("Bible Verse Bot is now running. Press CTRL-C to exit.")
+	slog.Info("Bible Verse Bot is now running. Press CTRL-C to exit.")
 
-	// Wait for termination signal
+	// Wait for a termination signal, reloading the configuration on SIGHUP meanwhile
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-	<-sc
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case <-hup:
+			_ = bot.reloadConfig("SIGHUP")
+		case <-sc:
+			break wait
+		}
+	}
 
-	log.Println("Received termination signal. Shutting down...")
+	slog.Info("Received termination signal. Shutting down...")
+
+	// Stop accepting commands and let in-flight handlers finish before closing the session
+	cancel()
+	if !bot.drain(ShutdownTimeout) {
+		return ExitShutdownTimeout
+	}
+	return ExitOK
 }
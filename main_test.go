@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"fits", "John 3:16", 20, "John 3:16"},
+		{"exactly fits", "John 3:16", 9, "John 3:16"},
+		{"word boundary", "the quick brown fox", 12, "the quick…"},
+		{"no word boundary", "abcdefghij", 5, "abcd…"},
+		{"multibyte", "\u00e9\u00e9\u00e9\u00e9\u00e9", 3, "\u00e9\u00e9…"},
+		{"combining marks", strings.Repeat("e\u0301", 5), 4, "e\u0301…"},
+		{"cyrillic words", "Ибо так возлюбил Бог мир", 15, "Ибо так…"},
+		{"emoji", "🙏🙏🙏🙏", 3, "🙏🙏…"},
+		{"skin tone", strings.Repeat("👍🏽", 3), 4, "👍🏽…"},
+		{"flags", "🇺🇸🇬🇧🇫🇷", 4, "🇺🇸…"},
+		{"zero width joiner", "👨‍👩‍👧👨‍👩‍👧", 6, "👨‍👩‍👧…"},
+		{"cluster too big", "👨‍👩‍👧", 3, "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.max {
+				t.Errorf("truncate(%q, %d) has %d runes, over the limit", tt.s, tt.max, n)
+			}
+			assertWholeClusters(t, tt.s, got)
+		})
+	}
+}
+
+// assertWholeClusters fails the test unless truncated, without its ellipsis, ends on a grapheme
+// cluster boundary of s
+func assertWholeClusters(t *testing.T, s, truncated string) {
+	t.Helper()
+
+	kept := strings.TrimSuffix(truncated, "…")
+	if !strings.HasPrefix(s, kept) {
+		t.Fatalf("%q is not a prefix of %q", kept, s)
+	}
+
+	boundaries := map[int]bool{0: true}
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		_, end := g.Positions()
+		boundaries[end] = true
+	}
+	if !boundaries[len(kept)] {
+		t.Errorf("%q cuts a grapheme cluster of %q", kept, s)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// lastAPILatency is how long the latest Bible API request took. Unlike the histogram it is
+// kept with metrics disabled, for !ping.
+var lastAPILatency atomic.Int64
+
+// botMetrics is nil unless metrics are enabled, in which case every observe call is a no-op
+var botMetrics *metrics
+
+// metrics holds the Prometheus collectors for command usage and Bible API calls
+type metrics struct {
+	registry   *prometheus.Registry
+	commands   *prometheus.CounterVec
+	apiLatency prometheus.Histogram
+	apiErrors  *prometheus.CounterVec
+}
+
+// newMetrics creates the collectors on a dedicated registry
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "verse_bot_commands_total",
+			Help: "Commands run, by command name.",
+		}, []string{"command"}),
+		apiLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "verse_bot_api_request_duration_seconds",
+			Help:    "Latency of Bible API requests.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "verse_bot_api_errors_total",
+			Help: "Bible API errors, by type.",
+		}, []string{"type"}),
+	}
+	m.registry.MustRegister(m.commands, m.apiLatency, m.apiErrors)
+	return m
+}
+
+// handler serves the registry in the Prometheus exposition format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeCommand counts a command run
+func (m *metrics) observeCommand(name string) {
+	if m == nil {
+		return
+	}
+	m.commands.WithLabelValues(name).Inc()
+}
+
+// observeAPILatency records how long a Bible API request took
+func (m *metrics) observeAPILatency(d time.Duration) {
+	lastAPILatency.Store(int64(d))
+	if m == nil {
+		return
+	}
+	m.apiLatency.Observe(d.Seconds())
+}
+
+// observeAPIError counts a Bible API error of the given type
+func (m *metrics) observeAPIError(kind string) {
+	if m == nil {
+		return
+	}
+	m.apiErrors.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs of the page navigation buttons
+const (
+	pagePrevID = "paginator:prev"
+	pageNextID = "paginator:next"
+)
+
+// paginator holds the pages of a long passage shown in one message
+type paginator struct {
+	verse   *BibleVerse
+	pages   []string
+	current int
+	expires time.Time
+
+	// render draws pages that are not a passage, such as the !books list, instead of verse
+	render func(text string, page, total int) *discordgo.MessageEmbed
+}
+
+// components renders the navigation buttons, disabling those at either end
+func (p *paginator) components() []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "◀", Style: discordgo.SecondaryButton, CustomID: pagePrevID, Disabled: p.current == 0},
+				discordgo.Button{Label: "▶", Style: discordgo.SecondaryButton, CustomID: pageNextID, Disabled: p.current == len(p.pages)-1},
+			},
+		},
+	}
+}
+
+// pageEmbed renders the current page of a paginator
+func (b *Bot) pageEmbed(p *paginator) *discordgo.MessageEmbed {
+	if p.render != nil {
+		return p.render(p.pages[p.current], p.current+1, len(p.pages))
+	}
+	return b.createVersePageEmbed(p.verse, p.pages[p.current], p.current+1, len(p.pages))
+}
+
+// paginatorStore tracks paginators by message ID until their TTL passes
+type paginatorStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	paginators map[string]*paginator
+}
+
+// newPaginatorStore creates a store whose paginators expire after ttl
+func newPaginatorStore(ttl time.Duration) *paginatorStore {
+	return &paginatorStore{
+		ttl:        ttl,
+		paginators: make(map[string]*paginator),
+	}
+}
+
+// Add registers a paginator for messageID
+func (ps *paginatorStore) Add(messageID string, p *paginator) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p.expires = time.Now().Add(ps.ttl)
+	ps.paginators[messageID] = p
+}
+
+// Turn moves the paginator for messageID by delta pages and returns it, or false if it has expired
+func (ps *paginatorStore) Turn(messageID string, delta int) (*paginator, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.paginators[messageID]
+	if !ok || time.Now().After(p.expires) {
+		delete(ps.paginators, messageID)
+		return nil, false
+	}
+
+	p.current = min(max(p.current+delta, 0), len(p.pages)-1)
+	p.expires = time.Now().Add(ps.ttl)
+	return p, true
+}
+
+// cleanupLoop garbage-collects expired paginators until ctx is cancelled
+func (ps *paginatorStore) cleanupLoop(ctx context.Context) {
+	ticker := time.NewTicker(ps.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ps.mu.Lock()
+			for id, p := range ps.paginators {
+				if now.After(p.expires) {
+					delete(ps.paginators, id)
+				}
+			}
+			ps.mu.Unlock()
+		}
+	}
+}
+
+// splitPages breaks text into pages of at most size runes, cutting between words
+func splitPages(text string, size int) []string {
+	var pages []string
+	var page strings.Builder
+	pageLen := 0
+
+	// Line breaks, such as chapter markers, are kept unless they fall on a page boundary
+	for i, line := range strings.Split(text, "\n") {
+		sep := byte(' ')
+		if i > 0 {
+			sep = '\n'
+		}
+		words := strings.Fields(line)
+		if len(words) == 0 && i > 0 && pageLen > 0 {
+			page.WriteByte('\n')
+			pageLen++
+		}
+
+		for _, word := range words {
+			wordLen := len([]rune(word))
+			if pageLen > 0 && pageLen+1+wordLen > size {
+				pages = append(pages, strings.TrimRight(page.String(), "\n"))
+				page.Reset()
+				pageLen = 0
+			}
+			if pageLen > 0 {
+				page.WriteByte(sep)
+				pageLen++
+			}
+			sep = ' '
+			page.WriteString(word)
+			pageLen += wordLen
+		}
+	}
+	if pageLen > 0 || len(pages) == 0 {
+		pages = append(pages, strings.TrimRight(page.String(), "\n"))
+	}
+
+	return pages
+}
+
+// sendVerse answers m with a verse, adding page navigation when it is too long for one embed.
+// extra components, such as the "Another" button, are added below a single-page verse.
+// The author is DMed the verse if the bot cannot post in the channel. It reports whether the verse was sent.
+func (b *Bot) sendVerse(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, verse *BibleVerse, extra ...discordgo.MessageComponent) bool {
+	send, p := b.verseMessage(m.GuildID, verse)
+	return b.deliverVerse(ctx, s, m, verse, send, p, extra...)
+}
+
+// deliverVerse sends a message built by verseMessage for verse, possibly with additions, the way sendVerse does
+func (b *Bot) deliverVerse(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, verse *BibleVerse, send *discordgo.MessageSend, p *paginator, extra ...discordgo.MessageComponent) bool {
+	if p == nil {
+		send.Components = extra
+	}
+	if b.mentionRequester(m.GuildID) {
+		mentionUser(send, m.Author.ID)
+	}
+	msg, err := b.replyMessage(ctx, s, m, send)
+	if err != nil {
+		slog.ErrorContext(ctx, "Embed send error", "channel_id", m.ChannelID, "error", err)
+		return false
+	}
+	b.posted.Set(msg.ID, verse)
+	if p != nil {
+		b.paginators.Add(msg.ID, p)
+	}
+	return true
+}
+
+// mentionUser prefixes msg with a mention of userID. Allowed mentions are limited to that user,
+// so neither the verse text nor a replied-to message can ping anyone else.
+func mentionUser(msg *discordgo.MessageSend, userID string) {
+	msg.Content = strings.TrimSpace("<@" + userID + "> " + msg.Content)
+	msg.AllowedMentions = &discordgo.MessageAllowedMentions{Users: []string{userID}}
+}
+
+// queueVerse is sendVerse for bulk posts: the message goes through the send queue. failed, when
+// set, is called if the message is dropped from a full queue or Discord never accepts it.
+func (b *Bot) queueVerse(s *discordgo.Session, channelID string, verse *BibleVerse, failed func()) bool {
+	guildID := ""
+	if channel, err := s.State.Channel(channelID); err == nil {
+		guildID = channel.GuildID
+	}
+
+	send, p := b.verseMessage(guildID, verse)
+	queued := b.sends.Enqueue(s, channelID, send, func(msg *discordgo.Message, err error) {
+		if err != nil {
+			if failed != nil {
+				failed()
+			}
+			return
+		}
+		b.posted.Set(msg.ID, verse)
+		if p != nil {
+			b.paginators.Add(msg.ID, p)
+		}
+	})
+	if !queued && failed != nil {
+		failed()
+	}
+	return queued
+}
+
+// verseMessage builds the message for a verse in the guild's style, with the paginator to register
+// once it is sent when an embed passage needs more than one page
+func (b *Bot) verseMessage(guildID string, verse *BibleVerse) (*discordgo.MessageSend, *paginator) {
+	if style := b.verseStyle(guildID); style != VerseStyleEmbed {
+		formatter, ok := b.formatter(style)
+		if ok {
+			send, err := formatter.Format(verse)
+			if err == nil {
+				return send, nil
+			}
+			slog.Error("Cannot format verse, sending embed instead", "style", style, "reference", verse.Title(), "error", err)
+		}
+	}
+
+	// Embeds page long passages with buttons rather than cutting them off
+	pages := splitPages(verse.Text(), PageSize)
+	if len(pages) == 1 {
+		send, _ := embedFormatter{b}.Format(verse)
+		return send, nil
+	}
+
+	p := &paginator{verse: verse, pages: pages}
+	return &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{b.pageEmbed(p)},
+		Components: p.components(),
+	}, p
+}
+
+// verseCardMessage builds a message showing the verse as an image card inside its embed.
+// The card scales its text to fit, so it never needs pagination.
+func (b *Bot) verseCardMessage(verse *BibleVerse) (*discordgo.MessageSend, error) {
+	card, err := renderVerseCard(verse, b.config.Load().EmbedColor)
+	if err != nil {
+		return nil, err
+	}
+
+	// The card already shows the text, so the embed keeps only its title, attribution and image
+	embed := b.createVerseEmbed(verse)
+	embed.Description = ""
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + CardFileName}
+
+	return &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{{Name: CardFileName, ContentType: "image/png", Reader: bytes.NewReader(card)}},
+	}, nil
+}
+
+// handlePageButton turns the page of a paginated passage in response to a button click
+func (b *Bot) handlePageButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	delta := 1
+	if i.MessageComponentData().CustomID == pagePrevID {
+		delta = -1
+	}
+
+	p, ok := b.paginators.Turn(i.Message.ID, delta)
+	if !ok {
+		ctx := withLocale(b.ctx, b.interactionLocale(i))
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: localize(ctx, "page_expired"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			slog.Error("Error responding to interaction", "interaction_id", i.ID, "error", err)
+		}
+		return
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{b.pageEmbed(p)},
+			Components: p.components(),
+		},
+	})
+	if err != nil {
+		slog.Error("Error updating paginated message", "interaction_id", i.ID, "error", err)
+	}
+}
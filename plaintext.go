@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MessageContentLimit is the most characters Discord accepts in a message's text
+const MessageContentLimit = 2000
+
+// isMissingEmbedPermission reports whether err is Discord refusing a message because the bot
+// may post in channelID but not embed links there. Discord uses the generic Missing Permissions
+// code for this, so the bot's channel permissions are checked to tell it apart.
+func isMissingEmbedPermission(s *discordgo.Session, channelID string, err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Message == nil || restErr.Message.Code != discordgo.ErrCodeMissingPermissions {
+		return false
+	}
+
+	perms, permErr := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	return permErr == nil && perms&discordgo.PermissionSendMessages != 0 && perms&discordgo.PermissionEmbedLinks == 0
+}
+
+// plainTextMessage copies msg with its embeds rendered into the message text. Buttons are
+// dropped since paging and rerolling work by editing the embed.
+func plainTextMessage(msg *discordgo.MessageSend) *discordgo.MessageSend {
+	plain := *msg
+	parts := []string{msg.Content}
+	for _, embed := range msg.Embeds {
+		parts = append(parts, embedPlainText(embed))
+	}
+	plain.Content = truncate(strings.TrimSpace(strings.Join(parts, "\n\n")), MessageContentLimit)
+	plain.Embeds = nil
+	plain.Components = nil
+	return &plain
+}
+
+// embedPlainText renders an embed's author, title, text, fields and footer as Markdown
+func embedPlainText(embed *discordgo.MessageEmbed) string {
+	var lines []string
+	if embed.Title != "" {
+		lines = append(lines, "**"+embed.Title+"**")
+	}
+	if embed.Author != nil && embed.Author.Name != "" {
+		lines = append(lines, "*"+embed.Author.Name+"*")
+	}
+	if embed.Description != "" {
+		lines = append(lines, embed.Description)
+	}
+	for _, field := range embed.Fields {
+		lines = append(lines, "**"+field.Name+"**", field.Value)
+	}
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		lines = append(lines, "-# "+embed.Footer.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitMessage breaks content into messages of at most limit characters, cutting between lines
+// so each line keeps its formatting. A line too long for one message is cut between words, or
+// anywhere if it has no spaces to cut at.
+func splitMessage(content string, limit int) []string {
+	var messages []string
+	var message strings.Builder
+	messageLen := 0
+	for _, line := range strings.Split(content, "\n") {
+		for _, piece := range splitLine(line, limit) {
+			pieceLen := utf8.RuneCountInString(piece)
+			if messageLen > 0 && messageLen+1+pieceLen > limit {
+				messages = append(messages, message.String())
+				message.Reset()
+				messageLen = 0
+			}
+			if message.Len() > 0 {
+				message.WriteByte('\n')
+				messageLen++
+			}
+			message.WriteString(piece)
+			messageLen += pieceLen
+		}
+	}
+	if message.Len() > 0 {
+		messages = append(messages, message.String())
+	}
+	return messages
+}
+
+// splitLine cuts a single line into pieces of at most limit characters, preferring to cut at spaces
+func splitLine(line string, limit int) []string {
+	var pieces []string
+	for utf8.RuneCountInString(line) > limit {
+		runes := []rune(line)
+		cut := strings.LastIndex(string(runes[:limit+1]), " ")
+		if cut <= 0 {
+			cut = len(string(runes[:limit]))
+		}
+		pieces = append(pieces, strings.TrimRight(line[:cut], " "))
+		line = strings.TrimLeft(line[cut:], " ")
+	}
+	return append(pieces, line)
+}
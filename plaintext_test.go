@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// testPassage builds a passage of about n characters, one numbered verse to a line
+func testPassage(n int) string {
+	var lines []string
+	for i, length := 1, 0; length < n; i++ {
+		line := fmt.Sprintf("**%d** And God said, Let there be light: and there was light.", i)
+		lines = append(lines, line)
+		length += len(line) + 1
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"short", "John 3:16", 1},
+		{"exactly the limit", strings.Repeat("a", MessageContentLimit), 1},
+		{"passage", testPassage(5000), 3},
+		{"one long line of words", strings.TrimSpace(strings.Repeat("word ", 1000)), 3},
+		{"one long word", strings.Repeat("a", 5000), 3},
+		{"multibyte", strings.Repeat("é", 5000), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := splitMessage(tt.content, MessageContentLimit)
+			if len(messages) != tt.want {
+				t.Fatalf("split into %d messages, want %d", len(messages), tt.want)
+			}
+			for i, message := range messages {
+				if n := utf8.RuneCountInString(message); n == 0 || n > MessageContentLimit {
+					t.Errorf("message %d has %d characters, want 1 to %d", i, n, MessageContentLimit)
+				}
+			}
+
+			// Only the whitespace at each cut may go missing
+			stripped := func(s string) string { return strings.Join(strings.Fields(s), "") }
+			if stripped(strings.Join(messages, "")) != stripped(tt.content) {
+				t.Error("messages do not hold all of the content in order")
+			}
+		})
+	}
+}
+
+func TestSplitMessageCutsBetweenLines(t *testing.T) {
+	passage := testPassage(5000)
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(passage, "\n") {
+		lines[line] = true
+	}
+
+	for i, message := range splitMessage(passage, MessageContentLimit) {
+		for _, line := range strings.Split(message, "\n") {
+			if !lines[line] {
+				t.Fatalf("message %d has line %q, want every verse line whole", i, line)
+			}
+		}
+	}
+}
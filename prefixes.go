@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPrefixLength is the longest custom prefix !setprefix accepts, in characters
+const MaxPrefixLength = 3
+
+// validPrefix reports whether prefix is 1 to MaxPrefixLength characters with no whitespace.
+// Anything that could be part of a mention is refused so the bot is not triggered by pings.
+func validPrefix(prefix string) bool {
+	n := utf8.RuneCountInString(prefix)
+	if n == 0 || n > MaxPrefixLength {
+		return false
+	}
+	if strings.ContainsAny(prefix, "@<>") {
+		return false
+	}
+	return strings.IndexFunc(prefix, unicode.IsSpace) == -1
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// activityTypes maps the names accepted in BOT_ACTIVITY_TYPE to Discord activity types
+var activityTypes = map[string]discordgo.ActivityType{
+	"playing":   discordgo.ActivityTypeGame,
+	"watching":  discordgo.ActivityTypeWatching,
+	"listening": discordgo.ActivityTypeListening,
+}
+
+// parseActivityType reads a BOT_ACTIVITY_TYPE value such as "watching"
+func parseActivityType(raw string) (discordgo.ActivityType, error) {
+	activityType, ok := activityTypes[strings.ToLower(strings.TrimSpace(raw))]
+	if !ok {
+		return 0, fmt.Errorf("BOT_ACTIVITY_TYPE must be playing, watching or listening, got %q", raw)
+	}
+	return activityType, nil
+}
+
+// statusesFromEnv reads BOT_STATUS, a "|"-separated list so statuses may contain commas,
+// defaulting to pointing people at the verse command
+func statusesFromEnv(prefix string) []string {
+	raw := os.Getenv("BOT_STATUS")
+	if raw == "" {
+		return []string{"for " + prefix + "verse"}
+	}
+
+	var statuses []string
+	for _, status := range strings.Split(raw, "|") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// updatePresence shows the current status from BOT_STATUS. Discord forgets the presence
+// when the session reconnects, so it is set again on every Ready.
+func (b *Bot) updatePresence(s *discordgo.Session) {
+	config := b.config.Load()
+	if len(config.BotStatuses) == 0 {
+		return
+	}
+
+	status := config.BotStatuses[int(b.statusIndex.Load())%len(config.BotStatuses)]
+	err := s.UpdateStatusComplex(discordgo.UpdateStatusData{
+		Activities: []*discordgo.Activity{{Name: status, Type: config.BotActivityType}},
+		Status:     string(discordgo.StatusOnline),
+	})
+	if err != nil {
+		slog.Warn("Error updating presence", "status", status, "error", err)
+	}
+}
+
+// rotatePresence moves to the next of several BOT_STATUS entries every BOT_STATUS_INTERVAL.
+// Statuses are read afresh on each turn so a reload applies, but the interval is fixed at startup.
+// It returns when ctx is cancelled.
+func (b *Bot) rotatePresence(ctx context.Context, s *discordgo.Session) {
+	ticker := time.NewTicker(b.config.Load().BotStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Presence rotation stopped")
+			return
+		case <-ticker.C:
+			if len(b.config.Load().BotStatuses) > 1 {
+				b.statusIndex.Add(1)
+				b.updatePresence(s)
+			}
+		}
+	}
+}
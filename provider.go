@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// VerseProvider fetches verses. Command handlers depend on this interface rather than
+// the HTTP client so that they can run against a fake provider.
+type VerseProvider interface {
+	// Random returns a random verse in the given translation
+	Random(ctx context.Context, translation string) (*BibleVerse, error)
+	// RandomFromBook returns a random verse from the named book
+	RandomFromBook(ctx context.Context, book, translation string) (*BibleVerse, error)
+	// RandomFromBooks returns a random verse from any of the books with the given IDs
+	RandomFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error)
+	// ByReference returns the passage for a reference such as "John 3:16"
+	ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error)
+}
+
+// bibleAPIProvider is the VerseProvider backed by bible-api.com
+type bibleAPIProvider struct{}
+
+func (bibleAPIProvider) Random(ctx context.Context, translation string) (*BibleVerse, error) {
+	return getBibleVerse(ctx, translation)
+}
+
+func (bibleAPIProvider) RandomFromBook(ctx context.Context, book, translation string) (*BibleVerse, error) {
+	return getRandomVerseFromBook(ctx, book, translation)
+}
+
+func (bibleAPIProvider) RandomFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error) {
+	return getRandomVerseFromBooks(ctx, bookIDs, translation)
+}
+
+func (bibleAPIProvider) ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	return getVerseByReference(ctx, ref, translation)
+}
+
+// failoverProvider serves verses from primary, switching to secondary when primary fails
+// for reasons other than the request itself being bad
+type failoverProvider struct {
+	primary, secondary         VerseProvider
+	primaryName, secondaryName string
+}
+
+// newFailoverProvider wraps the bible-api.com provider with bolls.life as its backup
+func newFailoverProvider() *failoverProvider {
+	return &failoverProvider{
+		primary:       bibleAPIProvider{},
+		secondary:     bollsProvider{},
+		primaryName:   "bible-api.com",
+		secondaryName: "bolls.life",
+	}
+}
+
+func (f *failoverProvider) Random(ctx context.Context, translation string) (*BibleVerse, error) {
+	return f.fetch(ctx, func(ctx context.Context, p VerseProvider) (*BibleVerse, error) {
+		return p.Random(ctx, translation)
+	})
+}
+
+func (f *failoverProvider) RandomFromBook(ctx context.Context, book, translation string) (*BibleVerse, error) {
+	return f.fetch(ctx, func(ctx context.Context, p VerseProvider) (*BibleVerse, error) {
+		return p.RandomFromBook(ctx, book, translation)
+	})
+}
+
+func (f *failoverProvider) RandomFromBooks(ctx context.Context, bookIDs []string, translation string) (*BibleVerse, error) {
+	return f.fetch(ctx, func(ctx context.Context, p VerseProvider) (*BibleVerse, error) {
+		return p.RandomFromBooks(ctx, bookIDs, translation)
+	})
+}
+
+func (f *failoverProvider) ByReference(ctx context.Context, ref, translation string) (*BibleVerse, error) {
+	return f.fetch(ctx, func(ctx context.Context, p VerseProvider) (*BibleVerse, error) {
+		return p.ByReference(ctx, ref, translation)
+	})
+}
+
+// fetch runs call against the primary within FailoverTimeout, leaving the rest of ctx for the secondary
+func (f *failoverProvider) fetch(ctx context.Context, call func(context.Context, VerseProvider) (*BibleVerse, error)) (*BibleVerse, error) {
+	primaryCtx, cancel := context.WithTimeout(ctx, FailoverTimeout)
+	verse, err := call(primaryCtx, f.primary)
+	cancel()
+	if err == nil {
+		slog.DebugContext(ctx, "Verse served", "provider", f.primaryName)
+		return verse, nil
+	}
+	if !shouldFailover(ctx, err) {
+		return nil, err
+	}
+
+	slog.WarnContext(ctx, "Verse provider failed, failing over", "provider", f.primaryName, "fallback", f.secondaryName, "error", err)
+	verse, fallbackErr := call(ctx, f.secondary)
+	if fallbackErr != nil {
+		slog.ErrorContext(ctx, "Fallback verse provider failed", "provider", f.secondaryName, "error", fallbackErr)
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Verse served", "provider", f.secondaryName)
+	return verse, nil
+}
+
+// shouldFailover reports whether err means the provider is unhealthy rather than the request being bad.
+// Nothing is retried once the caller's own ctx is done.
+func shouldFailover(ctx context.Context, err error) bool {
+	var bookErr *unknownBookError
+	switch {
+	case ctx.Err() != nil:
+		return false
+	case errors.Is(err, errPassageNotFound), errors.Is(err, errUnknownTranslation), errors.Is(err, errResponseTooLarge), errors.As(err, &bookErr):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a sliding-window limiter keyed by Discord user ID.
+// A limit of zero disables rate limiting.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+// newRateLimiter allows up to limit requests per user within window
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// Allow records a request for userID if permitted, otherwise returns how long until the next slot frees up
+func (r *rateLimiter) Allow(userID string) (bool, time.Duration) {
+	if r.limit <= 0 {
+		return true, 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	recent := pruneHits(r.hits[userID], now.Add(-r.window))
+	if len(recent) >= r.limit {
+		r.hits[userID] = recent
+		return false, recent[0].Add(r.window).Sub(now)
+	}
+
+	r.hits[userID] = append(recent, now)
+	return true, 0
+}
+
+// cleanupLoop periodically forgets users with no recent requests until ctx is cancelled
+func (r *rateLimiter) cleanupLoop(ctx context.Context) {
+	if r.limit <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.cleanup(now)
+		}
+	}
+}
+
+// cleanup drops every user whose requests have all left the window
+func (r *rateLimiter) cleanup(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := now.Add(-r.window)
+	for userID, hits := range r.hits {
+		if recent := pruneHits(hits, cutoff); len(recent) > 0 {
+			r.hits[userID] = recent
+		} else {
+			delete(r.hits, userID)
+		}
+	}
+}
+
+// pruneHits returns the timestamps after cutoff; hits are kept in chronological order
+func pruneHits(hits []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Reactions the bot acts on: VerseReactionEmoji answers a message with a random verse and
+// BookmarkReactionEmoji bookmarks the verse in one of the bot's own messages
+const (
+	VerseReactionEmoji    = "📖"
+	BookmarkReactionEmoji = "⭐"
+)
+
+// messageReactionAdd handles the reactions the bot acts on, ignoring its own and every other emoji
+func (b *Bot) messageReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	defer b.recoverPanic(s, r.ChannelID)
+
+	if r.UserID == s.State.User.ID {
+		return
+	}
+	switch r.Emoji.Name {
+	case VerseReactionEmoji:
+		b.verseReaction(s, r)
+	case BookmarkReactionEmoji:
+		b.bookmarkReaction(s, r)
+	}
+}
+
+// verseReaction posts a random verse in reply to the reacted message.
+// Failures and rate-limited reactions are only logged, since an error reply to a reaction would be noise.
+func (b *Bot) verseReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	// Stop accepting new work once shutdown has started
+	if b.ctx.Err() != nil {
+		return
+	}
+
+	// The reaction is a way of running !verse, so it follows that command's restrictions
+	if verseCommand, ok := b.findCommand("verse"); (ok && b.commandDisabled(r.GuildID, verseCommand)) || !b.channelAllowed(r.GuildID, r.ChannelID) {
+		return
+	}
+	if verseCommand, ok := b.findCommand("verse"); ok && b.inMaintenance(r.UserID, verseCommand) {
+		return
+	}
+
+	if ok, wait := b.verseLimiter.Allow(r.UserID); !ok {
+		slog.Debug("Ignoring rate-limited reaction", "user_id", r.UserID, "retry_after", wait)
+		return
+	}
+
+	defer b.trackHandler()()
+
+	slog.Info("Running verse reaction", "guild_id", r.GuildID, "channel_id", r.ChannelID, "user_id", r.UserID, "message_id", r.MessageID)
+	// Count the reaction as the command it stands in for, so !stats only lists real commands
+	b.countCommand("verse")
+
+	ctx, cancel := context.WithTimeout(b.ctx, b.config.Load().CommandTimeout)
+	defer cancel()
+
+	verse, err := b.verses.Random(ctx, b.translation(r.GuildID))
+	if err != nil {
+		slog.Error("Error fetching verse for reaction", "channel_id", r.ChannelID, "message_id", r.MessageID, "error", err)
+		return
+	}
+	b.recent.Remember(r.UserID, verse)
+
+	if err := b.replyWithVerse(ctx, s, r.GuildID, r.ChannelID, r.MessageID, r.UserID, verse); err != nil {
+		slog.Error("Embed send error", "channel_id", r.ChannelID, "error", err)
+	}
+}
+
+// bookmarkReaction saves the verse in a message the bot posted within PostedVerseTTL to the
+// reacting user's bookmarks, confirming by DM. Reactions to any other message are ignored.
+func (b *Bot) bookmarkReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	verse, ok := b.posted.Get(r.MessageID)
+	if !ok || b.ctx.Err() != nil {
+		return
+	}
+
+	defer b.trackHandler()()
+
+	slog.Info("Bookmarking verse from reaction", "guild_id", r.GuildID, "channel_id", r.ChannelID, "user_id", r.UserID, "message_id", r.MessageID)
+	b.countCommand("bookmark")
+
+	ctx := withLocale(b.ctx, b.guildLocale(s, r.GuildID))
+	added, err := b.bookmarks.Add(r.UserID, Bookmark{
+		Reference:   verse.Title(),
+		Translation: verse.Translation.Identifier,
+		SavedAt:     time.Now(),
+	})
+	if err != nil {
+		slog.Error("Bookmark save error", "user_id", r.UserID, "error", err)
+		return
+	}
+
+	text := localize(ctx, "bookmark_saved", verse.Title())
+	if !added {
+		text = localize(ctx, "bookmark_exists", verse.Title())
+	}
+	dm, err := s.UserChannelCreate(r.UserID)
+	if err == nil {
+		_, err = s.ChannelMessageSend(dm.ID, text)
+	}
+	if err != nil {
+		// Users who turned off DMs still get the bookmark, just without confirmation
+		slog.Debug("Cannot confirm bookmark by DM", "user_id", r.UserID, "error", err)
+	}
+}
+
+// replyWithVerse posts verse as a reply to messageID on behalf of userID, still posting if the
+// message has been deleted in the meantime. It serves both 📖 reactions and the message command.
+func (b *Bot) replyWithVerse(ctx context.Context, s *discordgo.Session, guildID, channelID, messageID, userID string, verse *BibleVerse) error {
+	failIfNotExists := false
+	send, p := b.verseMessage(guildID, verse)
+	if b.mentionRequester(guildID) {
+		mentionUser(send, userID)
+	}
+	send.Reference = &discordgo.MessageReference{
+		MessageID:       messageID,
+		ChannelID:       channelID,
+		GuildID:         guildID,
+		FailIfNotExists: &failIfNotExists,
+	}
+	msg, err := sendWithDMFallback(ctx, s, channelID, "", send)
+	if err != nil {
+		return err
+	}
+	b.posted.Set(msg.ID, verse)
+	if p != nil {
+		b.paginators.Add(msg.ID, p)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// requestIDKey is the context key under which a command's request ID is stored
+type requestIDKey struct{}
+
+// newRequestID returns a short random ID that ties together everything logged for one command
+func newRequestID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// withRequestID returns a context carrying the request ID of the command being handled
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestID returns the request ID carried by ctx, or "" outside a command
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// errorReply adds the request ID to an error message so users can quote it when asking for help
+func errorReply(ctx context.Context, text string) string {
+	if id := requestID(ctx); id != "" {
+		return text + "\n-# " + localize(ctx, "request_ref", id)
+	}
+	return text
+}
+
+// requestIDHandler adds the request ID from the context to every record logged with one,
+// so a command can be followed from dispatch through fetching to the reply with a single grep
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
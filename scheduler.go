@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// runDailyVerse posts a random verse to DAILY_VERSE_CHANNEL_ID and every guild's chosen channel,
+// and DMs it to every !subscribe subscriber, at the same time every day. A configuration reload
+// reschedules it. It returns when ctx is cancelled.
+func (b *Bot) runDailyVerse(ctx context.Context, s *discordgo.Session) {
+	for {
+		// The time is read afresh each round so a reloaded DAILY_VERSE_TIME takes effect
+		config := b.config.Load()
+		hour, minute, err := parseClock(config.DailyVerseTime)
+		if err != nil {
+			slog.Error("Daily verse disabled, invalid time", "error", err)
+			return
+		}
+		next := nextDailyRun(time.Now(), hour, minute, config.DailyVerseLocation)
+		slog.Info("Daily verse scheduled", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			slog.Info("Daily verse scheduler stopped")
+			return
+		case <-b.reloaded:
+			timer.Stop()
+		case <-timer.C:
+			webhookURL := b.config.Load().DailyVerseWebhookURL
+			if webhookURL != "" {
+				b.postDailyVerseWebhook(ctx, s, webhookURL)
+			}
+			targets := b.dailyTargets()
+			if len(targets) == 0 && webhookURL == "" {
+				slog.Info("No daily verse channels configured, nothing to post")
+			}
+			for channelID, translation := range targets {
+				b.postDailyVerse(ctx, s, channelID, translation)
+			}
+			// DMs are paced out for long subscriber lists, so they run alongside the schedule
+			go b.sendDailyDMs(ctx, s)
+		}
+	}
+}
+
+// dailyTargets maps each channel that gets the daily verse to the translation to post it in.
+// Guild settings are read at posting time so channels chosen with !dailychannel apply the same day.
+// DAILY_VERSE_CHANNEL_ID is left out when a webhook takes its place.
+func (b *Bot) dailyTargets() map[string]string {
+	targets := make(map[string]string)
+	if config := b.config.Load(); config.DailyVerseChannelID != "" && config.DailyVerseWebhookURL == "" {
+		targets[config.DailyVerseChannelID] = b.translation("")
+	}
+	for guildID, gc := range b.settings.All() {
+		if gc.DailyVerseChannelID != "" {
+			targets[gc.DailyVerseChannelID] = b.translation(guildID)
+		}
+	}
+	return targets
+}
+
+// postDailyVerse sends the verse of the day in translation to channelID
+func (b *Bot) postDailyVerse(ctx context.Context, s *discordgo.Session, channelID, translation string) {
+	defer b.trackHandler()()
+	defer b.recoverPanic(s, "")
+
+	if !b.dailyDebounce.Allow(channelID) {
+		slog.Info("Skipping daily verse, recently posted in channel", "channel_id", channelID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	// A post that never reaches the channel frees it up for a retry
+	verse, err := b.daily.Get(ctx, translation, b.verses.Random)
+	if err != nil {
+		b.dailyDebounce.Release(channelID)
+		slog.Error("Skipping daily verse, fetch failed", "channel_id", channelID, "error", err)
+		return
+	}
+
+	// Scheduled posts go through the send queue so they respect Discord's global rate limit
+	if b.queueVerse(s, channelID, verse, func() { b.dailyDebounce.Release(channelID) }) {
+		slog.Info("Daily verse queued", "channel_id", channelID, "reference", verse.Title())
+	}
+}
+
+// nextDailyRun returns the next time after now that falls on hour:minute in loc
+func nextDailyRun(now time.Time, hour, minute int, loc *time.Location) time.Time {
+	now = now.In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// runAutoVerse posts a random verse to AUTO_VERSE_CHANNEL_ID every AUTO_VERSE_INTERVAL, separately
+// from the daily verse. A configuration reload restarts the ticker with the new settings, or parks
+// it while the auto verse is disabled. It returns when ctx is cancelled.
+func (b *Bot) runAutoVerse(ctx context.Context, s *discordgo.Session) {
+	for {
+		config := b.config.Load()
+		if config.AutoVerseInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.autoVerseReloaded:
+				continue
+			}
+		}
+
+		slog.Info("Auto verse scheduled", "channel_id", config.AutoVerseChannelID, "every", config.AutoVerseInterval)
+		ticker := time.NewTicker(config.AutoVerseInterval)
+		stopped := b.tickAutoVerse(ctx, s, ticker.C, config.AutoVerseChannelID)
+		ticker.Stop()
+		if stopped {
+			slog.Info("Auto verse scheduler stopped")
+			return
+		}
+	}
+}
+
+// tickAutoVerse posts to channelID on every tick until a reload, returning false, or until ctx is
+// cancelled, returning true
+func (b *Bot) tickAutoVerse(ctx context.Context, s *discordgo.Session, tick <-chan time.Time, channelID string) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-b.autoVerseReloaded:
+			return false
+		case <-tick:
+			b.postAutoVerse(ctx, s, channelID)
+		}
+	}
+}
+
+// postAutoVerse sends a random verse in the channel's guild translation to channelID
+func (b *Bot) postAutoVerse(ctx context.Context, s *discordgo.Session, channelID string) {
+	defer b.trackHandler()()
+	defer b.recoverPanic(s, "")
+
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	guildID := ""
+	if channel, err := s.State.Channel(channelID); err == nil {
+		guildID = channel.GuildID
+	}
+	verse, err := b.verses.Random(ctx, b.translation(guildID))
+	if err != nil {
+		slog.Error("Skipping auto verse, fetch failed", "channel_id", channelID, "error", err)
+		return
+	}
+
+	if b.queueVerse(s, channelID, verse, nil) {
+		slog.Info("Auto verse queued", "channel_id", channelID, "reference", verse.Title())
+	}
+}
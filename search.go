@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SearchResult is a single verse matching a keyword search
+type SearchResult struct {
+	Reference string
+	Snippet   string
+}
+
+// searchResponse is the payload returned by the bolls.life search endpoint
+type searchResponse struct {
+	Total   int `json:"total"`
+	Results []struct {
+		Book    int    `json:"book"`
+		Chapter int    `json:"chapter"`
+		Verse   int    `json:"verse"`
+		Text    string `json:"text"`
+	} `json:"results"`
+}
+
+// htmlTag matches the markup bolls.life embeds in verse text
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// searchVerses finds up to MaxSearchResults verses containing keyword.
+// bible-api.com has no full text search, so this uses bolls.life instead.
+func searchVerses(ctx context.Context, keyword string) ([]SearchResult, error) {
+	query := url.Values{}
+	query.Set("search", keyword)
+	query.Set("match_case", "false")
+	query.Set("match_whole", "true")
+	query.Set("limit", fmt.Sprint(MaxSearchResults))
+	query.Set("page", "1")
+	endpoint := fmt.Sprintf("%s/v2/find/%s?%s", SearchAPIBase, SearchTranslation, query.Encode())
+
+	var resp searchResponse
+	if err := fetchFromAPI(ctx, endpoint, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, MaxSearchResults)
+	for _, r := range resp.Results {
+		if len(results) == MaxSearchResults {
+			break
+		}
+		book, ok := bookByNumber(r.Book)
+		if !ok {
+			continue
+		}
+
+		// Highlighted matches come back wrapped in <mark> tags
+		text := strings.NewReplacer("<mark>", "**", "</mark>", "**").Replace(r.Text)
+		results = append(results, SearchResult{
+			Reference: fmt.Sprintf("%s %d:%d", book.Name, r.Chapter, r.Verse),
+			Snippet:   strings.TrimSpace(htmlTag.ReplaceAllString(text, "")),
+		})
+	}
+
+	return results, nil
+}
+
+// createSearchEmbed lists search results as embed fields
+func (b *Bot) createSearchEmbed(ctx context.Context, keyword string, results []SearchResult) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: localize(ctx, "search_title", keyword),
+		Color: b.config.Load().EmbedColor,
+	}
+
+	for _, r := range results {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  r.Reference,
+			Value: r.Snippet,
+		})
+	}
+
+	return limitEmbed(embed)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sendJob is one queued message. done, when set, is called with the message once Discord accepts
+// it, or with the error that made the queue give up on it.
+type sendJob struct {
+	s         *discordgo.Session
+	channelID string
+	msg       *discordgo.MessageSend
+	done      func(*discordgo.Message, error)
+}
+
+// sendQueue serializes bulk sends, such as scheduled posts, through a single worker so a burst
+// cannot trip Discord's global rate limit. Interactive replies go straight to Discord instead.
+type sendQueue struct {
+	jobs chan sendJob
+}
+
+func newSendQueue(size int) *sendQueue {
+	return &sendQueue{jobs: make(chan sendJob, size)}
+}
+
+// Enqueue queues msg for channelID, reporting false if the queue is full and the message was dropped.
+// done is only called for queued messages.
+func (q *sendQueue) Enqueue(s *discordgo.Session, channelID string, msg *discordgo.MessageSend, done func(*discordgo.Message, error)) bool {
+	select {
+	case q.jobs <- sendJob{s: s, channelID: channelID, msg: msg, done: done}:
+		return true
+	default:
+		slog.Warn("Send queue full, dropping message", "channel_id", channelID)
+		return false
+	}
+}
+
+// run sends queued messages one at a time until ctx is cancelled
+func (q *sendQueue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if pending := len(q.jobs); pending > 0 {
+				slog.Warn("Send queue stopped with messages pending", "pending", pending)
+			}
+			return
+		case job := <-q.jobs:
+			q.send(ctx, job)
+		}
+	}
+}
+
+// send delivers one job, waiting out Discord's Retry-After whenever it answers 429
+func (q *sendQueue) send(ctx context.Context, job sendJob) {
+	// Scheduled posts may target a thread that has auto-archived since it was configured
+	if err := prepareThread(job.s, job.channelID); err != nil {
+		slog.Warn("Cannot prepare thread, sending anyway", "channel_id", job.channelID, "error", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		rewindFiles(job.msg)
+
+		// Turn off discordgo's own retry so the 429 comes back to us with its Retry-After
+		sent, err := job.s.ChannelMessageSendComplex(job.channelID, job.msg, discordgo.WithRetryOnRatelimit(false), discordgo.WithContext(ctx))
+		if err == nil {
+			job.finish(sent, nil)
+			return
+		}
+
+		// Retry without embeds in channels where the bot may post but not embed
+		if len(job.msg.Embeds) > 0 && isMissingEmbedPermission(job.s, job.channelID, err) {
+			slog.Warn("Missing permission to embed in channel, sending plain text", "channel_id", job.channelID)
+			job.msg = plainTextMessage(job.msg)
+			continue
+		}
+
+		var rateErr *discordgo.RateLimitError
+		if !errors.As(err, &rateErr) || attempt >= MaxSendAttempts {
+			slog.Error("Queued send failed", "channel_id", job.channelID, "attempt", attempt, "error", err)
+			job.finish(nil, err)
+			return
+		}
+
+		slog.Warn("Rate limited by Discord, waiting to retry", "channel_id", job.channelID, "retry_after", rateErr.RetryAfter, "attempt", attempt)
+		timer := time.NewTimer(rateErr.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			job.finish(nil, ctx.Err())
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// finish reports the outcome of the job to its done callback, if it has one
+func (job sendJob) finish(msg *discordgo.Message, err error) {
+	if job.done != nil {
+		job.done(msg, err)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// GuildConfig holds the settings a server has customised. Empty fields fall back to the global configuration.
+type GuildConfig struct {
+	Prefix              string   `json:"prefix,omitempty"`
+	Translation         string   `json:"translation,omitempty"`
+	DailyVerseChannelID string   `json:"daily_verse_channel_id,omitempty"`
+	DisabledCommands    []string `json:"disabled_commands,omitempty"`
+
+	// MentionRequester overrides MENTION_REQUESTER when set
+	MentionRequester *bool `json:"mention_requester,omitempty"`
+
+	// VerseStyle is one of VerseStyles, overriding VERSE_IMAGE when set
+	VerseStyle string `json:"verse_style,omitempty"`
+}
+
+// isZero reports whether the guild has nothing customised, so its entry can be dropped
+func (gc GuildConfig) isZero() bool {
+	return gc.Prefix == "" && gc.Translation == "" && gc.DailyVerseChannelID == "" && len(gc.DisabledCommands) == 0 &&
+		gc.MentionRequester == nil && gc.VerseStyle == ""
+}
+
+// SettingsStore persists GuildConfig per Discord guild ID, along with each user's reading Streak
+type SettingsStore interface {
+	// Get returns the guild's settings, zero if it has none
+	Get(guildID string) GuildConfig
+	// Update applies change to the guild's settings and saves them
+	Update(guildID string, change func(*GuildConfig)) error
+	// All returns a snapshot of every guild's settings
+	All() map[string]GuildConfig
+	// RecordStreak counts a read by the user on day and returns the updated streak
+	RecordStreak(userID string, day time.Time) (Streak, error)
+	// Streak returns the user's streak as last recorded
+	Streak(userID string) Streak
+}
+
+// settingsFile is the layout of the settings JSON file
+type settingsFile struct {
+	Guilds  map[string]GuildConfig `json:"guilds"`
+	Streaks map[string]Streak      `json:"streaks"`
+}
+
+// jsonSettingsStore keeps guild settings and streaks in memory and mirrors them to a JSON file
+type jsonSettingsStore struct {
+	mu      sync.RWMutex
+	path    string
+	byGuild map[string]GuildConfig
+	streaks map[string]Streak
+}
+
+// newJSONSettingsStore loads the settings saved at path, starting empty if the file does not exist
+func newJSONSettingsStore(path string) (*jsonSettingsStore, error) {
+	var file settingsFile
+	if err := readJSONFile(path, &file); err != nil {
+		return nil, fmt.Errorf("cannot load guild settings: %w", err)
+	}
+
+	store := &jsonSettingsStore{
+		path:    path,
+		byGuild: file.Guilds,
+		streaks: file.Streaks,
+	}
+	if store.byGuild == nil {
+		store.byGuild = make(map[string]GuildConfig)
+	}
+	if store.streaks == nil {
+		store.streaks = make(map[string]Streak)
+	}
+	return store, nil
+}
+
+func (js *jsonSettingsStore) Get(guildID string) GuildConfig {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	return js.byGuild[guildID]
+}
+
+func (js *jsonSettingsStore) Update(guildID string, change func(*GuildConfig)) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	// Copy the slice so callers still holding the old settings never see it change
+	gc := js.byGuild[guildID]
+	gc.DisabledCommands = slices.Clone(gc.DisabledCommands)
+	change(&gc)
+	if gc.isZero() {
+		delete(js.byGuild, guildID)
+	} else {
+		js.byGuild[guildID] = gc
+	}
+
+	if err := js.save(); err != nil {
+		return fmt.Errorf("cannot save guild settings: %w", err)
+	}
+	return nil
+}
+
+func (js *jsonSettingsStore) All() map[string]GuildConfig {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	all := make(map[string]GuildConfig, len(js.byGuild))
+	for guildID, gc := range js.byGuild {
+		all[guildID] = gc
+	}
+	return all
+}
+
+func (js *jsonSettingsStore) RecordStreak(userID string, day time.Time) (Streak, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	old := js.streaks[userID]
+	streak := old.advance(day)
+	if streak == old {
+		return streak, nil
+	}
+
+	js.streaks[userID] = streak
+	if err := js.save(); err != nil {
+		return streak, fmt.Errorf("cannot save streaks: %w", err)
+	}
+	return streak, nil
+}
+
+func (js *jsonSettingsStore) Streak(userID string) Streak {
+	js.mu.RLock()
+	defer js.mu.RUnlock()
+
+	return js.streaks[userID]
+}
+
+// save writes the whole store to its file. The caller must hold the write lock.
+func (js *jsonSettingsStore) save() error {
+	return writeJSONFile(js.path, settingsFile{Guilds: js.byGuild, Streaks: js.streaks})
+}
+
+// prefix returns the command prefix for a guild, falling back to the configured one
+func (b *Bot) prefix(guildID string) string {
+	if prefix := b.settings.Get(guildID).Prefix; prefix != "" {
+		return prefix
+	}
+	return b.config.Load().Prefix
+}
+
+// translation returns the translation a guild has selected, falling back to DEFAULT_TRANSLATION
+// and then DefaultTranslation. An empty guildID, as for DMs, skips straight to the global default.
+func (b *Bot) translation(guildID string) string {
+	if translation := b.settings.Get(guildID).Translation; translation != "" {
+		return translation
+	}
+	if translation := b.config.Load().DefaultTranslation; translation != "" {
+		return translation
+	}
+	return DefaultTranslation
+}
+
+// mentionRequester reports whether verse replies in a guild should ping the requester
+func (b *Bot) mentionRequester(guildID string) bool {
+	if mention := b.settings.Get(guildID).MentionRequester; mention != nil {
+		return *mention
+	}
+	return b.config.Load().MentionRequester
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestJSONSettingsStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]GuildConfig{
+		"111": {
+			Prefix:              "?",
+			Translation:         "kjv",
+			DailyVerseChannelID: "222",
+			DisabledCommands:    []string{"search", "compare"},
+		},
+		"333": {Translation: "asv"},
+	}
+	for guildID, gc := range want {
+		if err := store.Update(guildID, func(c *GuildConfig) { *c = gc }); err != nil {
+			t.Fatalf("Update(%s) error = %v", guildID, err)
+		}
+	}
+	// A guild whose settings are all cleared is dropped rather than saved empty
+	if err := store.Update("444", func(c *GuildConfig) { c.Prefix = "$" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update("444", func(c *GuildConfig) { c.Prefix = "" }); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatalf("reloading settings: %v", err)
+	}
+	if got := loaded.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded settings = %+v, want %+v", got, want)
+	}
+	if got := loaded.Get("111"); !reflect.DeepEqual(got, want["111"]) {
+		t.Errorf("Get(111) = %+v, want %+v", got, want["111"])
+	}
+	if got := loaded.Get("999"); !got.isZero() {
+		t.Errorf("Get(999) = %+v, want zero settings for an unknown guild", got)
+	}
+}
+
+func TestJSONSettingsStoreMissingFile(t *testing.T) {
+	store, err := newJSONSettingsStore(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("newJSONSettingsStore() error = %v, want an empty store", err)
+	}
+	if all := store.All(); len(all) != 0 {
+		t.Errorf("All() = %+v, want no settings", all)
+	}
+}
+
+func TestJSONSettingsStoreConcurrentUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const guilds = 20
+	var wg sync.WaitGroup
+	for i := range guilds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			guildID := fmt.Sprint(1000 + i)
+			if err := store.Update(guildID, func(c *GuildConfig) { c.Prefix = guildID }); err != nil {
+				t.Errorf("Update(%s) error = %v", guildID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	loaded, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(loaded.All()); got != guilds {
+		t.Errorf("reloaded %d guilds, want %d", got, guilds)
+	}
+}
+
+func TestTranslationFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		guild    string
+		envValue string
+		want     string
+	}{
+		{"argument wins", "KJV", "asv", "bbe", "kjv"},
+		{"guild setting", "", "asv", "bbe", "asv"},
+		{"DEFAULT_TRANSLATION", "", "", "bbe", "bbe"},
+		{"built-in default", "", "", "", DefaultTranslation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEFAULT_TRANSLATION", tt.envValue)
+			verses := &fakeProvider{verse: john316}
+			b := newTestBot(t, verses)
+			if tt.guild != "" {
+				if err := b.settings.Update("guild", func(gc *GuildConfig) { gc.Translation = tt.guild }); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := b.lookupVerse(context.Background(), "guild", tt.arg, "John 3:16"); err != nil {
+				t.Fatalf("lookupVerse() error = %v", err)
+			}
+			want := []string{"ByReference John 3:16 " + tt.want}
+			if calls := verses.Calls(); !slices.Equal(calls, want) {
+				t.Errorf("provider calls = %q, want %q", calls, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// slashCommands are the application commands registered with Discord
+var slashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "verse",
+		Description: "Get a random verse or look up a passage",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reference",
+				Description: "Passage to look up, e.g. John 3:16",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "translation",
+				Description: "Translation identifier, e.g. kjv",
+			},
+		},
+	},
+	{
+		Name:        "ping",
+		Description: "Check that the bot is responsive",
+	},
+	{
+		Name:        "hello",
+		Description: "Say hello to the bot",
+	},
+}
+
+// registerCommands brings Discord's application commands in line with slashCommands and
+// messageCommands, scoped to DevGuildID when set for faster testing. Only commands that are new,
+// changed or no longer wanted are sent, so a restart with nothing changed costs a single request
+// and doesn't wait on Discord to propagate commands again.
+func (b *Bot) registerCommands(s *discordgo.Session) error {
+	appID, guildID := s.State.User.ID, b.config.Load().DevGuildID
+	if guildID != "" {
+		slog.Info("Registering application commands on guild", "guild_id", guildID)
+	} else {
+		slog.Info("Registering application commands globally")
+	}
+
+	existing, err := s.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return fmt.Errorf("cannot list registered commands: %w", err)
+	}
+	registered := make(map[string]*discordgo.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		registered[commandKey(cmd)] = cmd
+	}
+
+	var created, updated, unchanged int
+	for _, cmd := range slices.Concat(slashCommands, messageCommands) {
+		key := commandKey(cmd)
+		current, ok := registered[key]
+		delete(registered, key)
+
+		switch {
+		case !ok:
+			if _, err := s.ApplicationCommandCreate(appID, guildID, cmd); err != nil {
+				return fmt.Errorf("cannot create %q command: %w", cmd.Name, err)
+			}
+			created++
+		case commandChanged(current, cmd):
+			if _, err := s.ApplicationCommandEdit(appID, guildID, current.ID, cmd); err != nil {
+				return fmt.Errorf("cannot update %q command: %w", cmd.Name, err)
+			}
+			updated++
+		default:
+			unchanged++
+		}
+	}
+
+	// Whatever is left is registered but no longer wanted
+	for _, cmd := range registered {
+		if err := s.ApplicationCommandDelete(appID, guildID, cmd.ID); err != nil {
+			return fmt.Errorf("cannot delete %q command: %w", cmd.Name, err)
+		}
+	}
+
+	slog.Info("Application commands registered", "created", created, "updated", updated, "deleted", len(registered), "unchanged", unchanged)
+	return nil
+}
+
+// commandKey identifies a command by type and name, since a slash command and a context-menu
+// action may share a name
+func commandKey(cmd *discordgo.ApplicationCommand) string {
+	cmdType := cmd.Type
+	if cmdType == 0 {
+		cmdType = discordgo.ChatApplicationCommand
+	}
+	return fmt.Sprintf("%d/%s", cmdType, cmd.Name)
+}
+
+// commandChanged reports whether the registered command differs from the wanted one in anything
+// the bot sets. Options are compared through their JSON, which is what Discord stores.
+func commandChanged(registered, wanted *discordgo.ApplicationCommand) bool {
+	if registered.Description != wanted.Description {
+		return true
+	}
+	was, err := json.Marshal(registered.Options)
+	if err != nil {
+		return true
+	}
+	now, err := json.Marshal(wanted.Options)
+	if err != nil {
+		return true
+	}
+	return !bytes.Equal(was, now)
+}
+
+// interactionCreate dispatches slash command, message command and button interactions
+func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer b.recoverPanic(s, i.ChannelID)
+
+	// Stop accepting new commands once shutdown has started
+	if b.ctx.Err() != nil {
+		return
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		defer b.trackHandler()()
+		if i.ApplicationCommandData().CommandType == discordgo.MessageApplicationCommand {
+			b.handleMessageCommand(s, i)
+			return
+		}
+		b.handleSlashCommand(s, i)
+
+	case discordgo.InteractionMessageComponent:
+		switch customID := i.MessageComponentData().CustomID; {
+		case customID == pagePrevID, customID == pageNextID:
+			b.handlePageButton(s, i)
+		case strings.HasPrefix(customID, anotherVerseIDPrefix):
+			defer b.trackHandler()()
+			b.handleAnotherButton(s, i)
+		}
+	}
+}
+
+// handleSlashCommand runs the slash command named in the interaction
+func (b *Bot) handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	name := i.ApplicationCommandData().Name
+
+	// Replies use the user's locale, then the guild's, where the catalog supports it
+	ctx, cancel := context.WithTimeout(withRequestID(withLocale(b.ctx, b.interactionLocale(i)), newRequestID()), b.config.Load().CommandTimeout)
+	defer cancel()
+
+	slog.InfoContext(ctx, "Running slash command", "guild_id", i.GuildID, "channel_id", i.ChannelID, "user_id", interactionUserID(i), "command", name)
+	b.countCommand(name)
+
+	outcome := AuditCompleted
+	defer func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			outcome = AuditTimedOut
+		}
+		b.audit.Record(ctx, interactionUserID(i), i.GuildID, i.ChannelID, "/"+name, outcome)
+	}()
+
+	// Slash commands share their names with prefix commands, so they are disabled together and
+	// answer in the same channels
+	if command, ok := b.findCommand(name); ok && b.commandDisabled(i.GuildID, command) {
+		outcome = AuditDisabled
+		respondEphemeral(s, i, localize(ctx, "command_disabled"))
+		return
+	}
+	if !b.channelAllowed(i.GuildID, i.ChannelID) {
+		outcome = AuditDisabled
+		respondEphemeral(s, i, localize(ctx, "channel_not_allowed"))
+		return
+	}
+	if command, ok := b.findCommand(name); ok && b.inMaintenance(interactionUserID(i), command) {
+		outcome = AuditMaintenance
+		respondEphemeral(s, i, localize(ctx, "maintenance"))
+		return
+	}
+
+	switch name {
+	case "hello":
+		respondInteraction(s, i, b.helloMessage(ctx, i.GuildID))
+
+	case "ping":
+		respondInteraction(s, i, localize(ctx, "pong"))
+
+	case "verse":
+		if ok, wait := b.verseLimiter.Allow(interactionUserID(i)); !ok {
+			b.respondError(s, i, rateLimitReply(ctx, wait))
+			return
+		}
+		b.handleVerseInteraction(ctx, s, i)
+	}
+}
+
+// handleVerseInteraction answers /verse, deferring the response while the verse is fetched
+func (b *Bot) handleVerseInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge right away since the API call may exceed Discord's response window
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deferring interaction", "interaction_id", i.ID, "error", err)
+		return
+	}
+
+	var translation, ref string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "reference":
+			ref = opt.StringValue()
+		case "translation":
+			translation = opt.StringValue()
+		}
+	}
+
+	verse, err := b.lookupVerse(ctx, i.GuildID, translation, ref)
+	if err != nil {
+		b.followupError(ctx, s, i, verseErrorReply(ctx, err))
+		return
+	}
+
+	edit := &discordgo.WebhookEdit{}
+	var pages *paginator
+	if texts := splitPages(verse.Text(), PageSize); len(texts) > 1 {
+		// Long passages get page navigation buttons
+		pages = &paginator{verse: verse, pages: texts}
+		components := pages.components()
+		edit.Embeds = &[]*discordgo.MessageEmbed{b.pageEmbed(pages)}
+		edit.Components = &components
+	} else {
+		edit.Embeds = &[]*discordgo.MessageEmbed{b.createVerseEmbed(verse)}
+	}
+	b.recent.Remember(interactionUserID(i), verse)
+
+	msg, err := s.InteractionResponseEdit(i.Interaction, edit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error editing interaction response", "interaction_id", i.ID, "error", err)
+		return
+	}
+	b.posted.Set(msg.ID, verse)
+	if pages != nil {
+		b.paginators.Add(msg.ID, pages)
+	}
+}
+
+// ephemeralErrors reports whether the slash command i runs keeps its errors to the invoking user
+func (b *Bot) ephemeralErrors(i *discordgo.InteractionCreate) bool {
+	command, ok := b.findCommand(i.ApplicationCommandData().Name)
+	return ok && command.EphemeralErrors
+}
+
+// respondError answers an interaction with an error or cooldown notice, ephemeral if the command asks for it
+func (b *Bot) respondError(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if b.ephemeralErrors(i) {
+		respondEphemeral(s, i, content)
+		return
+	}
+	respondInteraction(s, i, content)
+}
+
+// followupError reports an error for an interaction that was answered with a public deferred response.
+// That response cannot be made ephemeral after the fact, so it is deleted and the error sent as an
+// ephemeral followup instead, unless the command wants its errors public.
+func (b *Bot) followupError(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if !b.ephemeralErrors(i) {
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content}); err != nil {
+			slog.ErrorContext(ctx, "Error editing interaction response", "interaction_id", i.ID, "error", err)
+		}
+		return
+	}
+
+	if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+		slog.WarnContext(ctx, "Error deleting deferred interaction response", "interaction_id", i.ID, "error", err)
+	}
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error sending interaction followup", "interaction_id", i.ID, "error", err)
+	}
+}
+
+// respondInteraction sends a plain text response to an interaction with error handling
+func respondInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content},
+	})
+	if err != nil {
+		slog.Error("Error responding to interaction", "interaction_id", i.ID, "error", err)
+	}
+}
+
+// respondEphemeral answers an interaction with a message only the invoking user can see
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		slog.Error("Error responding to interaction", "interaction_id", i.ID, "error", err)
+	}
+}
+
+// interactionUserID returns the invoking user's ID for both guild and DM interactions
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Channels for the interaction tests; DENIED_CHANNELS only takes real snowflakes
+const (
+	testAllowedChannel = "100000000000000001"
+	testDeniedChannel  = "100000000000000002"
+)
+
+// newSlashCommand builds a /name interaction from a member of a guild
+func newSlashCommand(channelID, name string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction",
+		Token:     "token",
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   "guild",
+		ChannelID: channelID,
+		Member:    &discordgo.Member{User: &discordgo.User{ID: "user"}},
+		Data:      discordgo.ApplicationCommandInteractionData{Name: name},
+	}}
+}
+
+// newButtonClick builds a click on the button with customID under one of the bot's messages
+func newButtonClick(channelID, customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction",
+		Token:     "token",
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   "guild",
+		ChannelID: channelID,
+		Member:    &discordgo.Member{User: &discordgo.User{ID: "user"}},
+		Message:   &discordgo.Message{ID: "message", ChannelID: channelID},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, ComponentType: discordgo.ButtonComponent},
+	}}
+}
+
+func TestInteractionsFollowChannelRestrictions(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(b *Bot, s *discordgo.Session, i *discordgo.InteractionCreate)
+		build  func(channelID string) *discordgo.InteractionCreate
+	}{
+		{
+			name:   "slash command",
+			handle: (*Bot).handleSlashCommand,
+			build:  func(channelID string) *discordgo.InteractionCreate { return newSlashCommand(channelID, "verse") },
+		},
+		{
+			name:   "another button",
+			handle: (*Bot).handleAnotherButton,
+			build: func(channelID string) *discordgo.InteractionCreate {
+				return newButtonClick(channelID, anotherVerseID("web", ""))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DENIED_CHANNELS", testDeniedChannel)
+			verses := &fakeProvider{verse: john316}
+			b := newTestBot(t, verses)
+			s, discord := newTestSession(t)
+
+			tt.handle(b, s, tt.build(testDeniedChannel))
+			responses := discord.Responses()
+			if len(responses) != 1 || responses[0].Data == nil ||
+				responses[0].Data.Content != localize(context.Background(), "channel_not_allowed") ||
+				responses[0].Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+				t.Fatalf("responses in a denied channel = %+v, want one ephemeral channel_not_allowed reply", responses)
+			}
+			if calls := verses.Calls(); len(calls) != 0 {
+				t.Errorf("fetched %q in a denied channel, want nothing", calls)
+			}
+
+			tt.handle(b, s, tt.build(testAllowedChannel))
+			if calls := verses.Calls(); len(calls) != 1 {
+				t.Errorf("provider calls in an allowed channel = %q, want one fetch", calls)
+			}
+		})
+	}
+}
+
+func TestVerseShortcutsFollowVerseRestrictions(t *testing.T) {
+	tests := []struct {
+		name      string
+		restrict  func(t *testing.T, b *Bot)
+		wantReply string
+	}{
+		{
+			name: "verse disabled",
+			restrict: func(t *testing.T, b *Bot) {
+				if err := b.settings.Update("guild", func(gc *GuildConfig) { gc.DisabledCommands = []string{"verse"} }); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantReply: "command_disabled",
+		},
+		{
+			name:      "maintenance",
+			restrict:  func(t *testing.T, b *Bot) { b.maintenance.Store(true) },
+			wantReply: "maintenance",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verses := &fakeProvider{verse: john316}
+			b := newTestBot(t, verses)
+			tt.restrict(t, b)
+			s, discord := newTestSession(t)
+
+			b.handleAnotherButton(s, newButtonClick(testAllowedChannel, anotherVerseID("web", "")))
+			responses := discord.Responses()
+			if len(responses) != 1 || responses[0].Data == nil || responses[0].Data.Content != localize(context.Background(), tt.wantReply) {
+				t.Errorf("button responses = %+v, want one %s reply", responses, tt.wantReply)
+			}
+
+			// Reactions are ignored without a reply
+			b.messageReactionAdd(s, &discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+				UserID:    "user",
+				MessageID: "message",
+				ChannelID: testAllowedChannel,
+				GuildID:   "guild",
+				Emoji:     discordgo.Emoji{Name: VerseReactionEmoji},
+			}})
+			if sent := discord.Sent(); len(sent) != 0 {
+				t.Errorf("reaction sent %+v, want nothing", sent)
+			}
+
+			if calls := verses.Calls(); len(calls) != 0 {
+				t.Errorf("fetched %q, want nothing", calls)
+			}
+		})
+	}
+}
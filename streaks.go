@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Streak counts the consecutive days a user has read a verse
+type Streak struct {
+	Current int    `json:"current"`
+	Longest int    `json:"longest"`
+	LastDay string `json:"last_day"`
+}
+
+// advance counts a read on day: the same day changes nothing, the next day extends the
+// streak and any later day starts a new one. Days are compared as dates, so DST changes
+// and the length of the gap do not matter.
+func (s Streak) advance(day time.Time) Streak {
+	today := day.Format(time.DateOnly)
+	switch s.LastDay {
+	case today:
+		return s
+	case day.AddDate(0, 0, -1).Format(time.DateOnly):
+		s.Current++
+	default:
+		s.Current = 1
+	}
+	s.LastDay = today
+	s.Longest = max(s.Longest, s.Current)
+	return s
+}
+
+// asOf returns the streak as seen on day. A streak whose last read was before yesterday is broken.
+func (s Streak) asOf(day time.Time) Streak {
+	if s.LastDay != day.Format(time.DateOnly) && s.LastDay != day.AddDate(0, 0, -1).Format(time.DateOnly) {
+		s.Current = 0
+	}
+	return s
+}
+
+// streakDay is the current day on the bot's calendar, the one !dailyverse and the scheduler use
+func (b *Bot) streakDay() time.Time {
+	return time.Now().In(b.config.Load().DailyVerseLocation)
+}
+
+// recordStreak counts a verse read towards the user's streak. A failure only costs the streak, so it is logged.
+func (b *Bot) recordStreak(userID string) {
+	if _, err := b.settings.RecordStreak(userID, b.streakDay()); err != nil {
+		slog.Error("Error saving streak", "user_id", userID, "error", err)
+	}
+}
+
+// handleStreak reports the user's current and longest streak of days reading a verse
+func (b *Bot) handleStreak(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	streak := b.settings.Streak(m.Author.ID).asOf(b.streakDay())
+	if streak.Longest == 0 {
+		b.reply(ctx, s, m, localize(ctx, "streak_none", b.prefix(m.GuildID)))
+		return
+	}
+	b.reply(ctx, s, m, localize(ctx, "streak", streak.Current, streak.Longest))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreakAdvance(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Add(12 * time.Hour)
+	}
+	tests := []struct {
+		name  string
+		start Streak
+		on    time.Time
+		want  Streak
+	}{
+		{"first read", Streak{}, day("2024-05-01"), Streak{Current: 1, Longest: 1, LastDay: "2024-05-01"}},
+		{"same day", Streak{Current: 3, Longest: 5, LastDay: "2024-05-01"}, day("2024-05-01"), Streak{Current: 3, Longest: 5, LastDay: "2024-05-01"}},
+		{"next day continues", Streak{Current: 3, Longest: 5, LastDay: "2024-05-01"}, day("2024-05-02"), Streak{Current: 4, Longest: 5, LastDay: "2024-05-02"}},
+		{"new longest", Streak{Current: 5, Longest: 5, LastDay: "2024-05-01"}, day("2024-05-02"), Streak{Current: 6, Longest: 6, LastDay: "2024-05-02"}},
+		{"missed a day", Streak{Current: 3, Longest: 5, LastDay: "2024-05-01"}, day("2024-05-03"), Streak{Current: 1, Longest: 5, LastDay: "2024-05-03"}},
+		{"missed a month", Streak{Current: 9, Longest: 9, LastDay: "2024-04-01"}, day("2024-05-01"), Streak{Current: 1, Longest: 9, LastDay: "2024-05-01"}},
+		{"across months", Streak{Current: 1, Longest: 1, LastDay: "2024-04-30"}, day("2024-05-01"), Streak{Current: 2, Longest: 2, LastDay: "2024-05-01"}},
+		{"across years", Streak{Current: 1, Longest: 1, LastDay: "2023-12-31"}, day("2024-01-01"), Streak{Current: 2, Longest: 2, LastDay: "2024-01-01"}},
+		{"leap day", Streak{Current: 1, Longest: 1, LastDay: "2024-02-28"}, day("2024-02-29"), Streak{Current: 2, Longest: 2, LastDay: "2024-02-29"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.start.advance(tt.on); got != tt.want {
+				t.Errorf("advance() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreakAdvanceTimezones(t *testing.T) {
+	// 23:30 and 00:30 the next night on the bot's clock, an hour apart but on different days
+	auckland := time.FixedZone("UTC+12", 12*60*60)
+	late := time.Date(2024, 5, 1, 23, 30, 0, 0, auckland)
+	streak := Streak{}.advance(late).advance(late.Add(time.Hour))
+	if streak.Current != 2 || streak.LastDay != "2024-05-02" {
+		t.Errorf("reads either side of midnight gave %+v, want a streak of 2 ending 2024-05-02", streak)
+	}
+
+	// The same two instants fall on one day in UTC, so they count once
+	streak = Streak{}.advance(late.UTC()).advance(late.Add(time.Hour).UTC())
+	if streak.Current != 1 || streak.LastDay != "2024-05-01" {
+		t.Errorf("reads on one UTC day gave %+v, want a streak of 1 on 2024-05-01", streak)
+	}
+
+	// Days are counted by date, so the short day when the clocks go forward changes nothing
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	before := time.Date(2024, 3, 10, 0, 30, 0, 0, newYork)
+	after := time.Date(2024, 3, 11, 0, 30, 0, 0, newYork)
+	if gap := after.Sub(before); gap != 23*time.Hour {
+		t.Fatalf("gap = %v, want 23h across the DST change", gap)
+	}
+	streak = Streak{}.advance(before).advance(after)
+	if streak.Current != 2 {
+		t.Errorf("reads on consecutive days across a DST change gave %+v, want a streak of 2", streak)
+	}
+	streak = Streak{}.advance(before).advance(after.AddDate(0, 0, 1))
+	if streak.Current != 1 {
+		t.Errorf("reads two days apart across a DST change gave %+v, want a broken streak", streak)
+	}
+}
+
+func TestStreakAsOf(t *testing.T) {
+	streak := Streak{Current: 4, Longest: 7, LastDay: "2024-05-01"}
+	tests := []struct {
+		day  string
+		want int
+	}{
+		{"2024-05-01", 4},
+		{"2024-05-02", 4},
+		{"2024-05-03", 0},
+	}
+	for _, tt := range tests {
+		day, err := time.Parse(time.DateOnly, tt.day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := streak.asOf(day)
+		if got.Current != tt.want || got.Longest != 7 {
+			t.Errorf("asOf(%s) = %+v, want a current streak of %d and the longest kept", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestJSONSettingsStoreStreaks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update("guild", func(gc *GuildConfig) { gc.Prefix = "?" }); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		if _, err := store.RecordStreak("user", day.AddDate(0, 0, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Streaks and guild settings share the file without clobbering each other
+	loaded, err := newJSONSettingsStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Streak{Current: 3, Longest: 3, LastDay: "2024-05-03"}
+	if got := loaded.Streak("user"); got != want {
+		t.Errorf("reloaded streak = %+v, want %+v", got, want)
+	}
+	if got := loaded.Get("guild").Prefix; got != "?" {
+		t.Errorf("reloaded prefix = %q, want ?", got)
+	}
+}
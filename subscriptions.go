@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Subscription is a user's opt-in to receive the daily verse by DM, in the translation and
+// language of the server they subscribed from
+type Subscription struct {
+	Translation  string    `json:"translation"`
+	Locale       string    `json:"locale"`
+	SubscribedAt time.Time `json:"subscribed_at"`
+
+	// Failures counts daily DMs in a row Discord refused, reset by a successful one
+	Failures int `json:"failures,omitempty"`
+}
+
+// SubscriptionStore persists daily verse subscriptions per Discord user ID
+type SubscriptionStore interface {
+	// Subscribe adds a subscription for the user, reporting false if they were already subscribed
+	Subscribe(userID string, sub Subscription) (bool, error)
+	// Unsubscribe removes the user's subscription, reporting false if they had none
+	Unsubscribe(userID string) (bool, error)
+	// RecordDelivery resets the user's failure count after a delivered DM or adds one after a
+	// refused DM, returning the updated count
+	RecordDelivery(userID string, delivered bool) (int, error)
+	// All returns a snapshot of every subscription
+	All() map[string]Subscription
+}
+
+// jsonSubscriptionStore keeps subscriptions in memory and mirrors them to a JSON file
+type jsonSubscriptionStore struct {
+	mu     sync.Mutex
+	path   string
+	byUser map[string]Subscription
+}
+
+// newJSONSubscriptionStore loads the subscriptions saved at path, starting empty if the file does not exist
+func newJSONSubscriptionStore(path string) (*jsonSubscriptionStore, error) {
+	store := &jsonSubscriptionStore{
+		path:   path,
+		byUser: make(map[string]Subscription),
+	}
+	if err := readJSONFile(path, &store.byUser); err != nil {
+		return nil, fmt.Errorf("cannot load subscriptions: %w", err)
+	}
+	return store, nil
+}
+
+func (js *jsonSubscriptionStore) Subscribe(userID string, sub Subscription) (bool, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if _, ok := js.byUser[userID]; ok {
+		return false, nil
+	}
+
+	js.byUser[userID] = sub
+	if err := writeJSONFile(js.path, js.byUser); err != nil {
+		return false, fmt.Errorf("cannot save subscriptions: %w", err)
+	}
+	return true, nil
+}
+
+func (js *jsonSubscriptionStore) Unsubscribe(userID string) (bool, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if _, ok := js.byUser[userID]; !ok {
+		return false, nil
+	}
+
+	delete(js.byUser, userID)
+	if err := writeJSONFile(js.path, js.byUser); err != nil {
+		return false, fmt.Errorf("cannot save subscriptions: %w", err)
+	}
+	return true, nil
+}
+
+func (js *jsonSubscriptionStore) RecordDelivery(userID string, delivered bool) (int, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	sub, ok := js.byUser[userID]
+	if !ok {
+		return 0, nil
+	}
+
+	// Most deliveries succeed for users who never failed, so they skip the write
+	failures := sub.Failures + 1
+	if delivered {
+		failures = 0
+	}
+	if failures == sub.Failures {
+		return failures, nil
+	}
+
+	sub.Failures = failures
+	js.byUser[userID] = sub
+	if err := writeJSONFile(js.path, js.byUser); err != nil {
+		return failures, fmt.Errorf("cannot save subscriptions: %w", err)
+	}
+	return failures, nil
+}
+
+func (js *jsonSubscriptionStore) All() map[string]Subscription {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	all := make(map[string]Subscription, len(js.byUser))
+	for userID, sub := range js.byUser {
+		all[userID] = sub
+	}
+	return all
+}
+
+// handleSubscribe signs the user up for the daily verse by DM, in the server's translation
+func (b *Bot) handleSubscribe(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	added, err := b.subscriptions.Subscribe(m.Author.ID, Subscription{
+		Translation:  b.translation(m.GuildID),
+		Locale:       locale,
+		SubscribedAt: time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Subscription save error", "user_id", m.Author.ID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "subscribe_failed")))
+		return
+	}
+	if !added {
+		b.reply(ctx, s, m, localize(ctx, "subscribe_exists", b.prefix(m.GuildID)))
+		return
+	}
+
+	b.reply(ctx, s, m, localize(ctx, "subscribe_done", b.config.Load().DailyVerseTime, b.prefix(m.GuildID)))
+}
+
+// handleUnsubscribe stops the user's daily verse DMs
+func (b *Bot) handleUnsubscribe(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	removed, err := b.subscriptions.Unsubscribe(m.Author.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Subscription save error", "user_id", m.Author.ID, "error", err)
+		b.reply(ctx, s, m, errorReply(ctx, localize(ctx, "subscribe_failed")))
+		return
+	}
+	if !removed {
+		b.reply(ctx, s, m, localize(ctx, "unsubscribe_none", b.prefix(m.GuildID)))
+		return
+	}
+
+	b.reply(ctx, s, m, localize(ctx, "unsubscribe_done"))
+}
+
+// sendDailyDMs sends the verse of the day to every subscriber in batches of DMBatchSize, pausing
+// DMBatchDelay between batches so a long subscriber list stays clear of Discord's global rate limit.
+// Users who refuse the DM SUBSCRIPTION_MAX_FAILURES days in a row are unsubscribed.
+func (b *Bot) sendDailyDMs(ctx context.Context, s *discordgo.Session) {
+	defer b.trackHandler()()
+	defer b.recoverPanic(s, "")
+
+	subscribers := b.subscriptions.All()
+	if len(subscribers) == 0 {
+		return
+	}
+	slog.Info("Sending daily verse DMs", "subscribers", len(subscribers))
+
+	sent := 0
+	for userID, sub := range subscribers {
+		if sent > 0 && sent%DMBatchSize == 0 {
+			timer := time.NewTimer(DMBatchDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				slog.Warn("Daily verse DMs stopped by shutdown", "sent", sent, "subscribers", len(subscribers))
+				return
+			case <-timer.C:
+			}
+		}
+		b.sendDailyDM(ctx, s, userID, sub)
+		sent++
+	}
+	slog.Info("Daily verse DMs sent", "subscribers", len(subscribers))
+}
+
+// sendDailyDM sends the verse of the day to one subscriber and records whether Discord accepted it
+func (b *Bot) sendDailyDM(ctx context.Context, s *discordgo.Session, userID string, sub Subscription) {
+	ctx, cancel := context.WithTimeout(withLocale(ctx, sub.Locale), RequestTimeout)
+	defer cancel()
+
+	verse, err := b.daily.Get(ctx, sub.Translation, b.verses.Random)
+	if err != nil {
+		slog.Error("Skipping daily verse DM, fetch failed", "user_id", userID, "translation", sub.Translation, "error", err)
+		return
+	}
+
+	send, _ := b.verseMessage("", verse)
+	send.Content = truncate(strings.TrimSpace(localize(ctx, "subscription_dm", b.prefix(""))+"\n\n"+send.Content), MessageContentLimit)
+	// Paging works by editing the message, which the paginator isn't told about here
+	send.Components = nil
+
+	dm, err := s.UserChannelCreate(userID, discordgo.WithContext(ctx))
+	if err == nil {
+		_, err = s.ChannelMessageSendComplex(dm.ID, send, discordgo.WithContext(ctx))
+	}
+	if err != nil && !isDMRefused(err) {
+		slog.Error("Daily verse DM failed", "user_id", userID, "error", err)
+		return
+	}
+
+	failures, recordErr := b.subscriptions.RecordDelivery(userID, err == nil)
+	if recordErr != nil {
+		slog.Error("Subscription save error", "user_id", userID, "error", recordErr)
+	}
+	if err == nil {
+		return
+	}
+
+	slog.Warn("Daily verse DM refused", "user_id", userID, "failures", failures, "error", err)
+	if limit := b.config.Load().SubscriptionMaxFailures; limit > 0 && failures >= limit {
+		if _, err := b.subscriptions.Unsubscribe(userID); err != nil {
+			slog.Error("Subscription save error", "user_id", userID, "error", err)
+			return
+		}
+		slog.Info("Unsubscribed user after repeated refused DMs", "user_id", userID, "failures", failures)
+	}
+}
+
+// isDMRefused reports whether err is Discord declining to deliver a DM, usually because the user
+// turned off DMs from server members or blocked the bot
+func isDMRefused(err error) bool {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser {
+		return true
+	}
+	return isPermissionError(err)
+}
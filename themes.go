@@ -0,0 +1,34 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math/rand"
+	"strings"
+)
+
+// themesJSON maps theme names such as "hope" to curated verse references
+//
+//go:embed themes.json
+var themesJSON []byte
+
+// themes is the parsed form of themes.json, keyed by lowercase theme name
+var themes = mustLoadThemes(themesJSON)
+
+// mustLoadThemes parses the embedded themes, panicking since a bad file is a build mistake
+func mustLoadThemes(data []byte) map[string][]string {
+	var parsed map[string][]string
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		panic("invalid themes.json: " + err.Error())
+	}
+	return parsed
+}
+
+// themeReference picks a random reference for the named theme, reporting false when name is not a theme
+func themeReference(name string) (string, bool) {
+	refs := themes[strings.ToLower(name)]
+	if len(refs) == 0 {
+		return "", false
+	}
+	return refs[rand.Intn(len(refs))], true
+}
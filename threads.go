@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// prepareThread makes sure the bot can post in channelID when it is a thread: archived threads
+// are reopened and the bot joins the thread. Regular channels are left alone.
+func prepareThread(s *discordgo.Session, channelID string) error {
+	channel, err := s.State.Channel(channelID)
+	if err != nil {
+		if channel, err = s.Channel(channelID); err != nil {
+			return fmt.Errorf("cannot look up channel: %w", err)
+		}
+	}
+	if !channel.IsThread() {
+		return nil
+	}
+
+	if channel.ThreadMetadata != nil && channel.ThreadMetadata.Archived {
+		slog.Info("Unarchiving thread before posting", "channel_id", channelID)
+		archived := false
+		if _, err := s.ChannelEdit(channelID, &discordgo.ChannelEdit{Archived: &archived}); err != nil {
+			return fmt.Errorf("cannot unarchive thread: %w", err)
+		}
+	}
+
+	// Joining a thread the bot is already in is a no-op, so there is no need to check membership first
+	if err := s.ThreadJoin(channelID); err != nil {
+		return fmt.Errorf("cannot join thread: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// translationsResponse is the payload returned by the bible-api.com translation list endpoint
+type translationsResponse struct {
+	Translations []Translation `json:"translations"`
+}
+
+// translationList caches the translations bible-api.com offers, since the list rarely changes
+type translationList struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	fetched time.Time
+	list    []Translation
+}
+
+// newTranslationList creates a list that is refetched once it is older than ttl
+func newTranslationList(ttl time.Duration) *translationList {
+	return &translationList{ttl: ttl}
+}
+
+// Get returns the available translations. If the API cannot be reached the last fetched list is
+// used, and failing that the built-in SupportedTranslations identifiers.
+func (tl *translationList) Get(ctx context.Context) []Translation {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.list != nil && time.Since(tl.fetched) < tl.ttl {
+		return tl.list
+	}
+
+	var resp translationsResponse
+	err := fetchFromAPI(ctx, BibleAPIBase+"/data", &resp)
+	if err == nil && len(resp.Translations) > 0 {
+		tl.list, tl.fetched = resp.Translations, time.Now()
+		return tl.list
+	}
+
+	slog.WarnContext(ctx, "Cannot fetch translation list, using fallback", "error", err)
+	if tl.list != nil {
+		return tl.list
+	}
+	static := make([]Translation, 0, len(SupportedTranslations))
+	for _, id := range SupportedTranslations {
+		static = append(static, Translation{Identifier: id})
+	}
+	return static
+}
+
+// handleTranslations lists the translations that can be passed to !verse and !translation
+func (b *Bot) handleTranslations(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	b.replyEmbed(ctx, s, m, b.createTranslationsEmbed(ctx, b.translations.Get(ctx)))
+}
+
+// createTranslationsEmbed lists each translation identifier with its full name and language
+func (b *Bot) createTranslationsEmbed(ctx context.Context, translations []Translation) *discordgo.MessageEmbed {
+	var builder strings.Builder
+	for _, t := range translations {
+		builder.WriteString(fmt.Sprintf("`%s`", t.Identifier))
+		if t.Name != "" {
+			builder.WriteString(" — " + t.Name)
+		}
+		if t.Language != "" {
+			builder.WriteString(fmt.Sprintf(" (%s)", t.Language))
+		}
+		builder.WriteString("\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       localize(ctx, "translations_title"),
+		Description: builder.String(),
+		Color:       b.config.Load().EmbedColor,
+	}
+}
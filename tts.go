@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ttsRequest is the body posted to TTS_ENDPOINT. The service answers with the audio itself.
+type ttsRequest struct {
+	Text   string `json:"text"`
+	Format string `json:"format"`
+}
+
+// synthesizeSpeech asks the configured text-to-speech service to read text aloud, returning
+// the audio and its content type
+func synthesizeSpeech(ctx context.Context, endpoint, apiKey, text string) ([]byte, string, error) {
+	payload, err := json.Marshal(ttsRequest{Text: text, Format: TTSFormat})
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid TTS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("TTS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("TTS service answered with status %d", resp.StatusCode)
+	}
+
+	// Discord rejects uploads above its attachment limit, so there is no point reading more
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, MaxAudioBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading TTS response: %w", err)
+	}
+	if len(audio) > MaxAudioBytes {
+		return nil, "", fmt.Errorf("TTS audio is larger than %d bytes", MaxAudioBytes)
+	}
+	if len(audio) == 0 {
+		return nil, "", fmt.Errorf("TTS service returned no audio")
+	}
+
+	return audio, resp.Header.Get("Content-Type"), nil
+}
+
+// attachVerseAudio adds a spoken version of verse to msg. When TTS is not configured or
+// synthesis fails the text is still sent, with a note saying why there is no audio.
+func (b *Bot) attachVerseAudio(ctx context.Context, msg *discordgo.MessageSend, verse *BibleVerse) {
+	config := b.config.Load()
+	if config.TTSEndpoint == "" {
+		msg.Content = localize(ctx, "audio_off")
+		return
+	}
+
+	// Read the reference first, then the text without its Markdown
+	text := verse.Title() + ". " + strings.Join(strings.Fields(strings.ReplaceAll(verse.Text(), "**", "")), " ")
+	audio, contentType, err := synthesizeSpeech(ctx, config.TTSEndpoint, config.TTSAPIKey, text)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error synthesizing verse audio", "reference", verse.Title(), "error", err)
+		msg.Content = localize(ctx, "audio_failed")
+		return
+	}
+
+	msg.Files = append(msg.Files, &discordgo.File{
+		Name:        "verse" + audioExtension(contentType),
+		ContentType: contentType,
+		Reader:      bytes.NewReader(audio),
+	})
+}
+
+// audioExtension picks the file extension for a TTS content type, assuming TTSFormat when unknown
+func audioExtension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	default:
+		return "." + TTSFormat
+	}
+}
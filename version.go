@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Build information, injected at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// logBuildInfo records which build is running
+func logBuildInfo() {
+	slog.Info("Build info", "version", version, "commit", commit, "build_date", buildDate)
+}
+
+// handleVersion reports the running build
+func (b *Bot) handleVersion(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	b.replyEmbed(ctx, s, m, &discordgo.MessageEmbed{
+		Title: localize(ctx, "version_title"),
+		Color: b.config.Load().EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: localize(ctx, "version_version"), Value: version, Inline: true},
+			{Name: localize(ctx, "version_commit"), Value: commit, Inline: true},
+			{Name: localize(ctx, "version_built"), Value: buildDate, Inline: true},
+		},
+	})
+}
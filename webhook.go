@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// parseWebhookURL splits a Discord webhook URL such as
+// https://discord.com/api/webhooks/<id>/<token> into its ID and token
+func parseWebhookURL(raw string) (id, token string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" {
+		return "", "", fmt.Errorf("expected an https Discord webhook URL, got %q", raw)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	n := len(parts)
+	if n < 4 || parts[n-3] != "webhooks" || parts[n-4] != "api" || !isSnowflake(parts[n-2]) || parts[n-1] == "" {
+		return "", "", fmt.Errorf("expected a URL like https://discord.com/api/webhooks/<id>/<token>, got %q", raw)
+	}
+	return parts[n-2], parts[n-1], nil
+}
+
+// postDailyVerseWebhook sends the verse of the day through DAILY_VERSE_WEBHOOK_URL, so it shows
+// the webhook's name and avatar instead of the bot's
+func (b *Bot) postDailyVerseWebhook(ctx context.Context, s *discordgo.Session, webhookURL string) {
+	defer b.trackHandler()()
+	defer b.recoverPanic(s, "")
+
+	// The URL was validated with the rest of the configuration
+	id, token, err := parseWebhookURL(webhookURL)
+	if err != nil {
+		slog.Error("Skipping daily verse, invalid webhook", "error", err)
+		return
+	}
+	if !b.dailyDebounce.Allow("webhook:" + id) {
+		slog.Info("Skipping daily verse, recently posted through webhook", "webhook_id", id)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	// A post that never reaches the webhook frees it up for a retry
+	verse, err := b.daily.Get(ctx, b.translation(""), b.verses.Random)
+	if err != nil {
+		b.dailyDebounce.Release("webhook:" + id)
+		slog.Error("Skipping daily verse, fetch failed", "webhook_id", id, "error", err)
+		return
+	}
+
+	// Webhooks cannot carry the page buttons, so a long passage is cut to a single embed
+	_, err = s.WebhookExecute(id, token, false, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{b.createVerseEmbed(verse)},
+	})
+	if err != nil {
+		b.dailyDebounce.Release("webhook:" + id)
+		slog.Error("Error posting daily verse through webhook", "webhook_id", id, "error", err)
+		return
+	}
+	slog.Info("Daily verse posted through webhook", "webhook_id", id, "reference", verse.Title())
+}